@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"os"
 	"os/signal"
 	"syscall"
@@ -21,15 +22,23 @@ func main() {
 	if err != nil {
 		logger.Error(op, "error", err)
 	}
+	if args := flag.Args(); len(args) > 0 && args[0] == "migrate" {
+		if err := runMigrate(context.Background(), cfg.RepoConfig.DatabaseDSN, args[1:], logger); err != nil {
+			logger.Error(op, "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	initCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	var urlRepo repository.URLRepository
 
 	if cfg.RepoConfig.DatabaseDSN != "" {
-		urlRepo, err = repository.NewURLRepository(initCtx, "db", cfg.RepoConfig.DatabaseDSN, logger)
+		urlRepo, err = repository.NewURLRepository(initCtx, "db", cfg.RepoConfig, logger)
 	} else {
-		urlRepo, err = repository.NewURLRepository(initCtx, "file", cfg.RepoConfig.FileStoragePath, logger)
+		urlRepo, err = repository.NewURLRepository(initCtx, "file", cfg.RepoConfig, logger)
 	}
 	if err != nil && !os.IsNotExist(err) {
 		logger.Error(op, "error", err)
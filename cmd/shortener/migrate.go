@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ArtShib/urlshortener/internal/repository/postgres"
+)
+
+// runMigrate выполняет подкоманду migrate: up, down [-steps N], status
+func runMigrate(ctx context.Context, dsn string, args []string, logger *slog.Logger) (err error) {
+	const op = "main.runMigrate"
+	if dsn == "" {
+		return fmt.Errorf("%s: %w", op, errors.New("database dsn is empty"))
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("%s: %w", op, errors.New("usage: migrate up|down [-steps N]|status"))
+	}
+
+	migrator, closeDB, err := postgres.OpenMigrator(ctx, dsn, logger)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		err = errors.Join(err, closeDB())
+	}()
+
+	switch args[0] {
+	case "up":
+		count, err := migrator.Up(ctx)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		fmt.Printf("applied %d migration(s)\n", count)
+	case "down":
+		flags := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+		steps := flags.Int("steps", 1, "Number of migrations to roll back")
+		if err := flags.Parse(args[1:]); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		count, err := migrator.Down(ctx, *steps)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		fmt.Printf("rolled back %d migration(s)\n", count)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.Applied {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf("%s: unknown command %q", op, args[0])
+	}
+	return nil
+}
@@ -5,15 +5,21 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 
 	"github.com/ArtShib/urlshortener/internal/config"
 	"github.com/ArtShib/urlshortener/internal/httpserver"
 	"github.com/ArtShib/urlshortener/internal/lib/auth"
+	"github.com/ArtShib/urlshortener/internal/lib/blocklist"
 	"github.com/ArtShib/urlshortener/internal/lib/shortener"
+	"github.com/ArtShib/urlshortener/internal/model"
 	"github.com/ArtShib/urlshortener/internal/repository"
 	"github.com/ArtShib/urlshortener/internal/service"
 	"github.com/ArtShib/urlshortener/internal/workerpool/audit"
+	"github.com/ArtShib/urlshortener/internal/workerpool/clicks"
+	"github.com/ArtShib/urlshortener/internal/workerpool/expiration"
+	"github.com/ArtShib/urlshortener/internal/workerpool/purge"
 	"github.com/ArtShib/urlshortener/internal/workerpool/requestdeletion"
 )
 
@@ -29,6 +35,10 @@ type App struct {
 	EventService *service.EventService
 	WPoolDelete  *requestdeletion.DeletePool
 	WPoolEvent   *audit.WorkerPoolEvent
+	Sweeper      *expiration.Sweeper
+	Blocklist    *blocklist.Blocklist
+	Clicks       *clicks.Recorder
+	Purger       *purge.Purger
 }
 
 // NewApp конструктор App
@@ -40,12 +50,31 @@ func NewApp(ctx context.Context, cfg *config.Config, repo *repository.URLReposit
 		EventRepo: *eventRepo,
 		Logger:    log,
 	}
-	shortSvc := shortener.NewShortener()
-	app.URLService = service.NewURLService(app.URLRepo, cfg.ShortService, shortSvc, app.Logger)
+	shortSvc, err := shortener.NewShortener(cfg.ShortService.CodeStrategy, cfg.ShortService.CodeAlphabet, app.URLRepo)
+	if err != nil {
+		log.Error(op, "error", fmt.Errorf("%s: short code strategy: %w, using random", op, err))
+		shortSvc, _ = shortener.NewShortener(shortener.StrategyRandom, "", nil)
+	}
+	var checkers []service.URLChecker
+	if cfg.ShortService.BlocklistPath != "" {
+		app.Blocklist, err = blocklist.New(cfg.ShortService.BlocklistPath, cfg.ShortService.BlocklistReloadInterval, log)
+		if err != nil {
+			log.Error(op, "error", fmt.Errorf("%s: %w", op, err))
+		} else {
+			app.Blocklist.Start(ctx)
+			checkers = append(checkers, app.Blocklist)
+		}
+	}
+	app.URLService = service.NewURLService(app.URLRepo, cfg.ShortService, shortSvc, app.Logger, checkers...)
 	app.WPoolDelete = requestdeletion.NewWorkerPool(app.URLService, app.Logger, cfg.Concurrency.WorkerPoolDelete)
 	app.WPoolDelete.Start(ctx)
+	app.Sweeper = expiration.New(app.URLService, app.Logger, cfg.Concurrency.WorkerPoolExpiration)
+	app.Sweeper.Start(ctx)
+	app.Clicks = clicks.New(app.URLService, app.Logger, cfg.Concurrency.WorkerPoolClicks)
+	app.Clicks.Start(ctx)
+	app.Purger = purge.New(app.URLService, app.Logger, cfg.Concurrency.WorkerPoolPurge)
+	app.Purger.Start(ctx)
 	app.Auth = auth.NewAuthService("048ff4ea240a9fdeac8f1422733e9f3b8b0291c969652225e25c5f0f9f8da654139c9e21")
-	var err error
 	app.EventService, err = service.NewEventService(app.EventRepo, app.Logger)
 	if err != nil {
 		log.Error(op, "error", fmt.Errorf("%s: %w", op, err))
@@ -54,9 +83,19 @@ func NewApp(ctx context.Context, cfg *config.Config, repo *repository.URLReposit
 	if err == nil {
 		app.WPoolEvent.Start(ctx)
 	}
+	var trusted *net.IPNet
+	if cfg.HTTPServer.TrustedSubnet != "" {
+		if _, trusted, err = net.ParseCIDR(cfg.HTTPServer.TrustedSubnet); err != nil {
+			log.Error(op, "error", fmt.Errorf("%s: trusted subnet: %w, stats are disabled", op, err))
+		}
+	}
+	imports := model.ImportLimits{
+		MaxBytes: cfg.HTTPServer.ImportMaxBytes,
+		MaxRows:  cfg.HTTPServer.ImportMaxRows,
+	}
 	app.Server = &http.Server{
 		Addr:    app.Config.HTTPServer.ServerAddress,
-		Handler: httpserver.NewRouter(app.URLService, app.Logger, app.Auth, app.WPoolDelete, app.WPoolEvent),
+		Handler: httpserver.NewRouter(app.URLService, app.Logger, app.Auth, app.WPoolDelete, app.WPoolEvent, app.Clicks, app.Purger, trusted, imports),
 	}
 	return app
 }
@@ -78,10 +117,16 @@ func (a *App) Run() <-chan error {
 
 // Stop остановка сервисов для реализации graceful shutdown
 func (a *App) Stop(ctx context.Context) error {
+	errServer := a.Server.Shutdown(ctx)
 	a.WPoolDelete.Stop()
 	a.WPoolEvent.Stop()
+	a.Sweeper.Stop()
+	a.Clicks.Stop()
+	a.Purger.Stop()
+	if a.Blocklist != nil {
+		a.Blocklist.Stop()
+	}
 	errRepo := a.URLRepo.Close()
-	errServer := a.Server.Shutdown(ctx)
 
 	if err := errors.Join(errRepo, errServer); err != nil {
 		return err // fmt.Errorf("failed to stop app gracefully: %w", err)
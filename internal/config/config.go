@@ -3,6 +3,7 @@ package config
 import (
 	"flag"
 	"os"
+	"time"
 
 	"github.com/ArtShib/urlshortener/internal/model"
 	"github.com/caarlos0/env"
@@ -58,6 +59,9 @@ func (c *Config) LoadConfigFlag() {
 	if c.AuditConfig.AuditURL == "" {
 		flag.StringVar(&c.AuditConfig.AuditURL, "AUDIT_URL", "", "URL to audit")
 	}
+	if c.HTTPServer.TrustedSubnet == "" {
+		flag.StringVar(&c.HTTPServer.TrustedSubnet, "t", "", "Trusted subnet in CIDR notation for /api/stats")
+	}
 
 	flag.Parse()
 }
@@ -66,11 +70,36 @@ func (c *Config) LoadConfigFlag() {
 func MustLoadConfig() (*Config, error) {
 	var err error
 	cfg := Config{
-		HTTPServer:   &model.HTTPServerConfig{},
-		ShortService: &model.ShortServiceConfig{},
+		HTTPServer: &model.HTTPServerConfig{
+			ImportMaxBytes: 10 << 20,
+			ImportMaxRows:  10000,
+		},
+		ShortService: &model.ShortServiceConfig{
+			AliasPattern:   `^[A-Za-z0-9_-]+$`,
+			AliasMinLength: 3,
+			AliasMaxLength: 64,
+
+			AllowedSchemes: []string{"http", "https"},
+
+			BlocklistReloadInterval: 30 * time.Second,
+
+			CodeStrategy:   "random",
+			CodeLength:     8,
+			CodeMaxLength:  16,
+			CodeMaxRetries: 5,
+
+			PasswordMaxAttempts:   5,
+			PasswordAttemptWindow: 15 * time.Minute,
+
+			DeletedRetention: 7 * 24 * time.Hour,
+		},
 		RepoConfig: &model.RepositoryConfig{
-			FileStoragePath: os.Getenv("FILE_STORAGE_PATH"),
-			DatabaseDSN:     os.Getenv("DATABASE_DSN"),
+			FileStoragePath:  os.Getenv("FILE_STORAGE_PATH"),
+			DatabaseDSN:      os.Getenv("DATABASE_DSN"),
+			FileSyncPolicy:   "always",
+			FileSyncInterval: time.Second,
+			SnapshotInterval: 5 * time.Minute,
+			JournalMaxSize:   10 << 20,
 		},
 		AuditConfig: &model.AuditConfig{},
 		Concurrency: &model.Concurrency{
@@ -84,6 +113,19 @@ func MustLoadConfig() (*Config, error) {
 				CountWorkers:   3,
 				EventChainSize: 100,
 			},
+			WorkerPoolExpiration: &model.WorkerPoolExpiration{
+				Interval:  time.Minute,
+				BatchSize: 500,
+			},
+			WorkerPoolClicks: &model.WorkerPoolClicks{
+				BufferSize:    10000,
+				BatchSize:     500,
+				FlushInterval: time.Second,
+			},
+			WorkerPoolPurge: &model.WorkerPoolPurge{
+				Interval:  time.Hour,
+				BatchSize: 500,
+			},
 		},
 	}
 	err = cfg.LoadConfigEnv()
@@ -0,0 +1,28 @@
+// Package apierror сопоставляет ошибки сервисного слоя с HTTP статусами.
+package apierror
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// Status возвращает HTTP статус и текст для ошибок, вызванных данными запроса.
+// ok == false, если ошибка не клиентская и отвечать нужно 500.
+func Status(err error) (status int, message string, ok bool) {
+	var reqErr *model.RequestError
+	switch {
+	case errors.Is(err, model.ErrURLBlocked) && errors.As(err, &reqErr):
+		return http.StatusUnprocessableEntity, reqErr.Error(), true
+	case errors.Is(err, model.ErrShortCodeConflict):
+		return http.StatusConflict, "alias is already taken", true
+	case errors.Is(err, model.ErrURLNotFound):
+		return http.StatusNotFound, "url not found", true
+	case errors.Is(err, model.ErrShortCodeExhausted):
+		return http.StatusServiceUnavailable, "no free short code, try again later", true
+	case errors.As(err, &reqErr):
+		return http.StatusBadRequest, reqErr.Error(), true
+	}
+	return 0, "", false
+}
@@ -0,0 +1,86 @@
+package clickstats
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/httpserver/handlers/apierror"
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// URLService интерфейс сервиса для получения статистики переходов.
+type URLService interface {
+	ClickStats(ctx context.Context, req model.ClickStatsRequest) (*model.ClickStats, error)
+}
+
+// New конструктор HandlerFunc для получения статистики переходов по ссылке пользователя.
+// Параметры: granularity (hour|day), from и to в формате RFC 3339.
+func New(log *slog.Logger, svc URLService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "ClickStats.Get"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		log.Info("received request")
+
+		userID, ok := r.Context().Value(model.UserIDKey).(string)
+		if !ok || userID == "" {
+			log.Error("Unauthorized", "error", http.StatusText(http.StatusUnauthorized))
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		req, err := parseRequest(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.UserID = userID
+		req.ShortCode = chi.URLParam(r, "shortCode")
+
+		stats, err := svc.ClickStats(r.Context(), req)
+		if status, message, ok := apierror.Status(err); ok {
+			http.Error(w, message, status)
+			return
+		}
+		if err != nil {
+			log.Error("service ClickStats", "error", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(stats); err != nil {
+			log.Error("Encode response", "error", err)
+		}
+	}
+}
+
+// parseRequest читает параметры статистики из query string
+func parseRequest(query url.Values) (model.ClickStatsRequest, error) {
+	req := model.ClickStatsRequest{
+		Granularity: query.Get("granularity"),
+	}
+	for name, dst := range map[string]*time.Time{"from": &req.From, "to": &req.To} {
+		raw := query.Get(name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return req, model.NewRequestError(model.ErrInvalidTimeRange, name+" must be in RFC 3339 format")
+		}
+		*dst = parsed
+	}
+	return req, nil
+}
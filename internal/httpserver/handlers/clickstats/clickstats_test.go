@@ -0,0 +1,140 @@
+package clickstats
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockURLService struct {
+	mock.Mock
+}
+
+func (m *MockURLService) ClickStats(ctx context.Context, req model.ClickStatsRequest) (*model.ClickStats, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ClickStats), args.Error(1)
+}
+
+func TestClickStatsHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	day := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		userID         string
+		query          string
+		mockFunc       func(m *MockURLService)
+		expectedStatus int
+		expectedBody   string
+		isJSONResponse bool
+	}{
+		{
+			name:   "Success",
+			userID: "1",
+			query:  "?granularity=day&from=2025-01-02T00:00:00Z",
+			mockFunc: func(m *MockURLService) {
+				m.On("ClickStats", mock.Anything, model.ClickStatsRequest{UserID: "1", ShortCode: "abc", Granularity: "day", From: day}).
+					Return(&model.ClickStats{
+						ShortCode:   "abc",
+						Total:       3,
+						Granularity: "day",
+						From:        day,
+						To:          day.Add(24 * time.Hour),
+						Series:      []model.ClickPoint{{Time: day, Clicks: 3}},
+						Referrers:   map[string]int64{"": 3},
+						Agents:      map[string]int64{"desktop": 3},
+						Countries:   map[string]int64{"ZZ": 3},
+					}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"short_code":"abc","total":3,"granularity":"day","from":"2025-01-02T00:00:00Z","to":"2025-01-03T00:00:00Z",
+				"series":[{"time":"2025-01-02T00:00:00Z","clicks":3}],"referrers":{"":3},"agents":{"desktop":3},"countries":{"ZZ":3}}`,
+			isJSONResponse: true,
+		},
+		{
+			name:           "Unauthorized",
+			mockFunc:       func(m *MockURLService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized\n",
+		},
+		{
+			name:           "InvalidFrom",
+			userID:         "1",
+			query:          "?from=yesterday",
+			mockFunc:       func(m *MockURLService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid time range: from must be in RFC 3339 format\n",
+		},
+		{
+			name:   "NotFound",
+			userID: "1",
+			mockFunc: func(m *MockURLService) {
+				m.On("ClickStats", mock.Anything, model.ClickStatsRequest{UserID: "1", ShortCode: "abc"}).
+					Return(nil, model.ErrURLNotFound).
+					Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "url not found\n",
+		},
+		{
+			name:   "InternalError",
+			userID: "1",
+			mockFunc: func(m *MockURLService) {
+				m.On("ClickStats", mock.Anything, model.ClickStatsRequest{UserID: "1", ShortCode: "abc"}).
+					Return(nil, errors.New("database error")).
+					Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Internal Server Error\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc := new(MockURLService)
+			test.mockFunc(svc)
+
+			handler := New(logger, svc)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/user/urls/abc/stats"+test.query, nil)
+			routeCtx := chi.NewRouteContext()
+			routeCtx.URLParams.Add("shortCode", "abc")
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx)
+			if test.userID != "" {
+				ctx = context.WithValue(ctx, model.UserIDKey, test.userID)
+			}
+			req = req.WithContext(ctx)
+
+			w := httptest.NewRecorder()
+			handler(w, req)
+
+			resp := w.Result()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+			if test.isJSONResponse {
+				assert.JSONEq(t, test.expectedBody, string(body))
+			} else {
+				assert.Equal(t, test.expectedBody, string(body))
+			}
+			svc.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// Package editurl предоставляет изменение адреса и описания короткой ссылки её владельцем.
+package editurl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/ArtShib/urlshortener/internal/httpserver/handlers/apierror"
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// URLService интерфейс сервиса для изменения ссылки.
+type URLService interface {
+	UpdateURL(ctx context.Context, userID, shortCode string, req model.RequestEditURL) (*model.URL, error)
+}
+
+// New конструктор HandlerFunc для изменения ссылки.
+// Тело запроса: {"original_url": "...", "tags": [...], "title": "...", "note": "...", "preview": true,
+// "passthrough": true, "utm": {"source": "..."}}, все поля необязательны,
+// непереданные поля не меняются. В ответе изменённая ссылка.
+func New(log *slog.Logger, svc URLService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "EditURL.Patch"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		log.Info("received request")
+		model.AuditFromContext(r.Context()).Action = model.ActionEdit
+
+		userID, ok := r.Context().Value(model.UserIDKey).(string)
+		if !ok || userID == "" {
+			log.Error("Unauthorized", "error", http.StatusText(http.StatusUnauthorized))
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		var req model.RequestEditURL
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				log.Error("close body", "error", err)
+			}
+		}()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error("Body decode", "error", err)
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+
+		url, err := svc.UpdateURL(r.Context(), userID, chi.URLParam(r, "shortCode"), req)
+		if errors.Is(err, model.ErrURLConflict) {
+			http.Error(w, "url is already shortened by another link", http.StatusConflict)
+			return
+		}
+		if status, message, ok := apierror.Status(err); ok {
+			if errors.Is(err, model.ErrURLBlocked) {
+				audit := model.AuditFromContext(r.Context())
+				audit.Action = model.ActionBlocked
+				audit.OriginalURL = req.OriginalURL
+			}
+			http.Error(w, message, status)
+			return
+		}
+		if err != nil {
+			log.Error("service UpdateURL", "error", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		model.AuditFromContext(r.Context()).OriginalURL = url.OriginalURL
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		response := model.URLUser{
+			UUID:            url.UUID,
+			ShortURL:        url.ShortURL,
+			OriginalURL:     url.OriginalURL,
+			CreatedAt:       url.CreatedAt,
+			DeletedFlag:     url.DeletedFlag,
+			Tags:            url.Tags,
+			Title:           url.Title,
+			Note:            url.Note,
+			PreviewRequired: url.PreviewRequired,
+			Passthrough:     url.Passthrough,
+			UTM:             url.UTM,
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Error("Encode response", "error", err)
+		}
+	}
+}
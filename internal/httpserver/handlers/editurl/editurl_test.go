@@ -0,0 +1,193 @@
+package editurl
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockURLService struct {
+	mock.Mock
+}
+
+func (m *MockURLService) UpdateURL(ctx context.Context, userID, shortCode string, req model.RequestEditURL) (*model.URL, error) {
+	args := m.Called(ctx, userID, shortCode, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.URL), args.Error(1)
+}
+
+func TestEditURLHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		userID         string
+		body           string
+		mockFunc       func(m *MockURLService)
+		expectedStatus int
+		expectedBody   string
+		expectedAction string
+		expectedURL    string
+		isJSONResponse bool
+	}{
+		{
+			name:   "Success",
+			userID: "1",
+			body:   `{"original_url":"https://google.com/fixed"}`,
+			mockFunc: func(m *MockURLService) {
+				m.On("UpdateURL", mock.Anything, "1", "abc", model.RequestEditURL{OriginalURL: "https://google.com/fixed"}).
+					Return(&model.URL{UUID: "abc", ShortURL: "http://localhost/abc", OriginalURL: "https://google.com/fixed", CreatedAt: created}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"uuid":"abc","short_url":"http://localhost/abc","original_url":"https://google.com/fixed",
+				"created_at":"2025-01-02T03:04:05Z","is_deleted":false}`,
+			expectedAction: model.ActionEdit,
+			expectedURL:    "https://google.com/fixed",
+			isJSONResponse: true,
+		},
+		{
+			name:   "Metadata",
+			userID: "1",
+			body:   `{"tags":["work"],"title":"Docs"}`,
+			mockFunc: func(m *MockURLService) {
+				m.On("UpdateURL", mock.Anything, "1", "abc", model.RequestEditURL{Tags: &[]string{"work"}, Title: ptr("Docs")}).
+					Return(&model.URL{UUID: "abc", ShortURL: "http://localhost/abc", OriginalURL: "https://google.com/",
+						CreatedAt: created, Tags: []string{"work"}, Title: "Docs"}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"uuid":"abc","short_url":"http://localhost/abc","original_url":"https://google.com/",
+				"created_at":"2025-01-02T03:04:05Z","is_deleted":false,"tags":["work"],"title":"Docs"}`,
+			expectedAction: model.ActionEdit,
+			expectedURL:    "https://google.com/",
+			isJSONResponse: true,
+		},
+		{
+			name:           "Unauthorized",
+			body:           `{"original_url":"https://google.com/fixed"}`,
+			mockFunc:       func(m *MockURLService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized\n",
+			expectedAction: model.ActionEdit,
+		},
+		{
+			name:           "BadBody",
+			userID:         "1",
+			body:           `{`,
+			mockFunc:       func(m *MockURLService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Bad Request\n",
+			expectedAction: model.ActionEdit,
+		},
+		{
+			name:   "NotOwner",
+			userID: "2",
+			body:   `{"original_url":"https://google.com/fixed"}`,
+			mockFunc: func(m *MockURLService) {
+				m.On("UpdateURL", mock.Anything, "2", "abc", model.RequestEditURL{OriginalURL: "https://google.com/fixed"}).
+					Return(nil, model.ErrURLNotFound).
+					Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "url not found\n",
+			expectedAction: model.ActionEdit,
+		},
+		{
+			name:   "InvalidURL",
+			userID: "1",
+			body:   `{"original_url":"ftp://google.com"}`,
+			mockFunc: func(m *MockURLService) {
+				m.On("UpdateURL", mock.Anything, "1", "abc", model.RequestEditURL{OriginalURL: "ftp://google.com"}).
+					Return(nil, model.NewRequestError(model.ErrInvalidURL, `scheme "ftp" is not allowed`)).
+					Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid url: scheme \"ftp\" is not allowed\n",
+			expectedAction: model.ActionEdit,
+		},
+		{
+			name:   "Conflict",
+			userID: "1",
+			body:   `{"original_url":"https://yandex.ru/"}`,
+			mockFunc: func(m *MockURLService) {
+				m.On("UpdateURL", mock.Anything, "1", "abc", model.RequestEditURL{OriginalURL: "https://yandex.ru/"}).
+					Return(nil, model.ErrURLConflict).
+					Once()
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "url is already shortened by another link\n",
+			expectedAction: model.ActionEdit,
+		},
+		{
+			name:   "InternalError",
+			userID: "1",
+			body:   `{"original_url":"https://google.com/fixed"}`,
+			mockFunc: func(m *MockURLService) {
+				m.On("UpdateURL", mock.Anything, "1", "abc", model.RequestEditURL{OriginalURL: "https://google.com/fixed"}).
+					Return(nil, errors.New("database error")).
+					Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Internal Server Error\n",
+			expectedAction: model.ActionEdit,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc := new(MockURLService)
+			test.mockFunc(svc)
+
+			handler := New(logger, svc)
+
+			req := httptest.NewRequest(http.MethodPatch, "/api/user/urls/abc", strings.NewReader(test.body))
+			routeCtx := chi.NewRouteContext()
+			routeCtx.URLParams.Add("shortCode", "abc")
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx)
+			if test.userID != "" {
+				ctx = context.WithValue(ctx, model.UserIDKey, test.userID)
+			}
+			ctx, audit := model.NewAuditContext(ctx)
+			req = req.WithContext(ctx)
+
+			w := httptest.NewRecorder()
+			handler(w, req)
+
+			resp := w.Result()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+			assert.Equal(t, test.expectedAction, audit.Action)
+			assert.Equal(t, test.expectedURL, audit.OriginalURL)
+			assert.Empty(t, resp.Header.Get("OriginalURL"), "audit details are not sent to the client")
+			if test.isJSONResponse {
+				assert.JSONEq(t, test.expectedBody, string(body))
+			} else {
+				assert.Equal(t, test.expectedBody, string(body))
+			}
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
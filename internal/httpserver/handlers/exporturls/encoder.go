@@ -0,0 +1,136 @@
+package exporturls
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// Форматы выгрузки
+const (
+	formatCSV    = "csv"
+	formatNDJSON = "ndjson"
+	formatJSON   = "json"
+)
+
+// csvHeader колонки CSV выгрузки, original_url и tags совпадают с колонками импорта
+var csvHeader = []string{
+	"uuid", "short_url", "original_url", "created_at", "is_deleted", "deleted_at",
+	"expires_at", "is_expired", "max_clicks", "clicks", "tags", "title", "note",
+}
+
+// encoder пишет ссылки выгрузки в одном формате
+type encoder interface {
+	contentType() string
+	begin() error
+	write(url model.URLExport) error
+	end() error
+	flush() error
+}
+
+// newEncoder encoder для format, nil при неизвестном формате
+func newEncoder(format string, w io.Writer) encoder {
+	switch format {
+	case formatCSV:
+		return &csvEncoder{w: csv.NewWriter(w)}
+	case formatNDJSON:
+		return &jsonEncoder{w: w, enc: json.NewEncoder(w)}
+	case formatJSON:
+		return &jsonEncoder{w: w, enc: json.NewEncoder(w), array: true}
+	}
+	return nil
+}
+
+// csvEncoder выгрузка в CSV с заголовком, метки разделены запятыми
+type csvEncoder struct {
+	w      *csv.Writer
+	record []string
+}
+
+func (e *csvEncoder) contentType() string { return "text/csv; charset=utf-8" }
+
+func (e *csvEncoder) begin() error {
+	return e.w.Write(csvHeader)
+}
+
+func (e *csvEncoder) write(url model.URLExport) error {
+	e.record = append(e.record[:0],
+		url.UUID,
+		url.ShortURL,
+		url.OriginalURL,
+		formatTime(&url.CreatedAt),
+		strconv.FormatBool(url.DeletedFlag),
+		formatTime(url.DeletedAt),
+		formatTime(url.ExpiresAt),
+		strconv.FormatBool(url.ExpiredFlag),
+		strconv.Itoa(url.MaxClicks),
+		strconv.FormatInt(url.Clicks, 10),
+		strings.Join(url.Tags, ","),
+		url.Title,
+		url.Note,
+	)
+	return e.w.Write(e.record)
+}
+
+func (e *csvEncoder) end() error { return nil }
+
+func (e *csvEncoder) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// formatTime время в RFC 3339, пустая строка для nil
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// jsonEncoder выгрузка в NDJSON, с array в один JSON массив
+type jsonEncoder struct {
+	w     io.Writer
+	enc   *json.Encoder
+	array bool
+	count int
+}
+
+func (e *jsonEncoder) contentType() string {
+	if e.array {
+		return "application/json"
+	}
+	return "application/x-ndjson"
+}
+
+func (e *jsonEncoder) begin() error {
+	if !e.array {
+		return nil
+	}
+	_, err := io.WriteString(e.w, "[\n")
+	return err
+}
+
+func (e *jsonEncoder) write(url model.URLExport) error {
+	if e.array && e.count > 0 {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	e.count++
+	return e.enc.Encode(url)
+}
+
+func (e *jsonEncoder) end() error {
+	if !e.array {
+		return nil
+	}
+	_, err := io.WriteString(e.w, "]\n")
+	return err
+}
+
+func (e *jsonEncoder) flush() error { return nil }
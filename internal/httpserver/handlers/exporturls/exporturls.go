@@ -0,0 +1,107 @@
+// Package exporturls предоставляет потоковую выгрузку ссылок пользователя в CSV, NDJSON и JSON.
+package exporturls
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// flushEvery через сколько ссылок выгрузка отправляется клиенту
+const flushEvery = 100
+
+// URLService интерфейс сервиса для выгрузки ссылок.
+type URLService interface {
+	ExportURLs(ctx context.Context, userID string, yield func(model.URLExport) error) error
+}
+
+// New конструктор HandlerFunc для выгрузки ссылок пользователя.
+// Параметр format: csv (по умолчанию), ndjson или json. Ответ передаётся частями по мере чтения из хранилища.
+func New(log *slog.Logger, svc URLService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "ExportURLs.Get"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		log.Info("received request")
+
+		userID, ok := r.Context().Value(model.UserIDKey).(string)
+		if !ok || userID == "" {
+			log.Error("Unauthorized", "error", http.StatusText(http.StatusUnauthorized))
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = formatCSV
+		}
+		enc := newEncoder(format, w)
+		if enc == nil {
+			http.Error(w, "format must be csv, ndjson or json", http.StatusBadRequest)
+			return
+		}
+
+		controller := http.NewResponseController(w)
+		flush := func() error {
+			if err := enc.flush(); err != nil {
+				return err
+			}
+			if err := controller.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				return err
+			}
+			return nil
+		}
+		started := false
+		start := func() error {
+			started = true
+			w.Header().Set("Content-Type", enc.contentType())
+			w.Header().Set("Content-Disposition", `attachment; filename="links.`+format+`"`)
+			w.WriteHeader(http.StatusOK)
+			return enc.begin()
+		}
+
+		count := 0
+		err := svc.ExportURLs(r.Context(), userID, func(url model.URLExport) error {
+			if !started {
+				if err := start(); err != nil {
+					return err
+				}
+			}
+			if err := enc.write(url); err != nil {
+				return err
+			}
+			if count++; count%flushEvery == 0 {
+				return flush()
+			}
+			return nil
+		})
+		if err != nil {
+			log.Error("service ExportURLs", "error", err)
+			if !started {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+			return
+		}
+		if !started {
+			if err := start(); err != nil {
+				log.Error("write response", "error", err)
+				return
+			}
+		}
+		if err := enc.end(); err != nil {
+			log.Error("write response", "error", err)
+			return
+		}
+		if err := flush(); err != nil {
+			log.Error("write response", "error", err)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package exporturls
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockURLService struct {
+	mock.Mock
+}
+
+func (m *MockURLService) ExportURLs(ctx context.Context, userID string, yield func(model.URLExport) error) error {
+	args := m.Called(ctx, userID)
+	for _, url := range args.Get(0).([]model.URLExport) {
+		if err := yield(url); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func TestExportURLsHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	deleted := created.Add(time.Hour)
+	urls := []model.URLExport{
+		{UUID: "aaa", ShortURL: "http://localhost/aaa", OriginalURL: "https://google.com", CreatedAt: created,
+			Clicks: 7, Tags: []string{"docs", "work"}, Title: "Search, main"},
+		{UUID: "bbb", ShortURL: "http://localhost/bbb", OriginalURL: "https://yandex.ru", CreatedAt: created,
+			DeletedFlag: true, DeletedAt: &deleted, MaxClicks: 5},
+	}
+
+	tests := []struct {
+		name                string
+		userID              string
+		query               string
+		mockFunc            func(m *MockURLService)
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+		isJSONResponse      bool
+	}{
+		{
+			name:   "CSV",
+			userID: "1",
+			mockFunc: func(m *MockURLService) {
+				m.On("ExportURLs", mock.Anything, "1").Return(urls, nil).Once()
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedBody: "uuid,short_url,original_url,created_at,is_deleted,deleted_at,expires_at,is_expired,max_clicks,clicks,tags,title,note\n" +
+				"aaa,http://localhost/aaa,https://google.com,2025-01-02T03:04:05Z,false,,,false,0,7,\"docs,work\",\"Search, main\",\n" +
+				"bbb,http://localhost/bbb,https://yandex.ru,2025-01-02T03:04:05Z,true,2025-01-02T04:04:05Z,,false,5,0,,,\n",
+		},
+		{
+			name:   "NDJSON",
+			userID: "1",
+			query:  "?format=ndjson",
+			mockFunc: func(m *MockURLService) {
+				m.On("ExportURLs", mock.Anything, "1").Return(urls[1:], nil).Once()
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/x-ndjson",
+			expectedBody: `{"uuid":"bbb","short_url":"http://localhost/bbb","original_url":"https://yandex.ru",` +
+				`"created_at":"2025-01-02T03:04:05Z","is_deleted":true,"deleted_at":"2025-01-02T04:04:05Z","max_clicks":5,"clicks":0}` + "\n",
+		},
+		{
+			name:   "JSON",
+			userID: "1",
+			query:  "?format=json",
+			mockFunc: func(m *MockURLService) {
+				m.On("ExportURLs", mock.Anything, "1").Return(urls, nil).Once()
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody: `[
+				{"uuid":"aaa","short_url":"http://localhost/aaa","original_url":"https://google.com","created_at":"2025-01-02T03:04:05Z",
+				 "is_deleted":false,"clicks":7,"tags":["docs","work"],"title":"Search, main"},
+				{"uuid":"bbb","short_url":"http://localhost/bbb","original_url":"https://yandex.ru","created_at":"2025-01-02T03:04:05Z",
+				 "is_deleted":true,"deleted_at":"2025-01-02T04:04:05Z","max_clicks":5,"clicks":0}]`,
+			isJSONResponse: true,
+		},
+		{
+			name:   "EmptyJSON",
+			userID: "1",
+			query:  "?format=json",
+			mockFunc: func(m *MockURLService) {
+				m.On("ExportURLs", mock.Anything, "1").Return([]model.URLExport{}, nil).Once()
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody:        `[]`,
+			isJSONResponse:      true,
+		},
+		{
+			name:                "UnknownFormat",
+			userID:              "1",
+			query:               "?format=xml",
+			mockFunc:            func(m *MockURLService) {},
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        "format must be csv, ndjson or json\n",
+		},
+		{
+			name:   "InternalError",
+			userID: "1",
+			mockFunc: func(m *MockURLService) {
+				m.On("ExportURLs", mock.Anything, "1").Return([]model.URLExport{}, errors.New("database error")).Once()
+			},
+			expectedStatus:      http.StatusInternalServerError,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        "Internal Server Error\n",
+		},
+		{
+			name:                "Unauthorized",
+			mockFunc:            func(m *MockURLService) {},
+			expectedStatus:      http.StatusUnauthorized,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        "Unauthorized\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc := new(MockURLService)
+			test.mockFunc(svc)
+
+			handler := New(logger, svc)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/user/urls/export"+test.query, nil)
+			if test.userID != "" {
+				req = req.WithContext(context.WithValue(req.Context(), model.UserIDKey, test.userID))
+			}
+
+			w := httptest.NewRecorder()
+			handler(w, req)
+
+			resp := w.Result()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+			assert.Equal(t, test.expectedContentType, resp.Header.Get("Content-Type"))
+			if test.isJSONResponse {
+				assert.JSONEq(t, test.expectedBody, string(body))
+			} else {
+				assert.Equal(t, test.expectedBody, string(body))
+			}
+			svc.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package getid
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// blockedPage предупреждение вместо перехода по заблокированной ссылке
+var blockedPage = template.Must(template.New("blocked").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<title>Link blocked</title>
+</head>
+<body>
+<h1>This link has been blocked</h1>
+<p>The destination was flagged as unsafe{{if .}}: {{.}}{{end}}.</p>
+</body>
+</html>
+`))
+
+// blocked отвечает страницей-предупреждением браузерам и текстом остальным клиентам
+func blocked(w http.ResponseWriter, r *http.Request, err error) {
+	var reason string
+	var reqErr *model.RequestError
+	if errors.As(err, &reqErr) {
+		reason = reqErr.Reason
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if !acceptsHTML(r) {
+		message := model.ErrURLBlocked.Error()
+		if reason != "" {
+			message += ": " + reason
+		}
+		http.Error(w, message, http.StatusForbidden)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusForbidden)
+	_ = blockedPage.Execute(w, reason)
+}
@@ -0,0 +1,33 @@
+package getid
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/lib/useragent"
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// newClick собирает данные перехода для аналитики. Из Referer сохраняется только хост
+func newClick(r *http.Request, uuid string) model.Click {
+	return model.Click{
+		UUID:     uuid,
+		Time:     time.Now().UTC(),
+		Referrer: referrerHost(r.Referer()),
+		Agent:    useragent.Classify(r.UserAgent()),
+		Country:  model.UnknownCountry,
+	}
+}
+
+func referrerHost(referrer string) string {
+	if referrer == "" {
+		return ""
+	}
+	u, err := url.Parse(referrer)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
@@ -2,8 +2,10 @@ package getid
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/ArtShib/urlshortener/internal/model"
 	"github.com/go-chi/chi/v5"
@@ -13,10 +15,24 @@ import (
 // URLService интерфейс сервиса для получения оригинального url.
 type URLService interface {
 	GetID(ctx context.Context, shortCode string) (*model.URL, error)
+	ConsumeClick(ctx context.Context, shortCode string) (bool, error)
+	Unlock(ctx context.Context, url *model.URL, password string) error
+	CheckTarget(ctx context.Context, originalURL string) error
+}
+
+// ClickRecorder интерфейс буферизованной записи переходов.
+type ClickRecorder interface {
+	AddClick(click model.Click)
 }
 
 // New конструктор HandlerFunc для получения оригинального url.
-func New(log *slog.Logger, svc URLService) http.HandlerFunc {
+// Для защищённых паролем ссылок GET отдаёт форму, а POST формы проверяет пароль.
+// Код с окончанием + или параметр preview=1 показывают страницу предпросмотра вместо перехода,
+// ссылки с обязательным предпросмотром показывают её, пока посетитель не подтвердит переход.
+// Адрес перехода выбирает первое подходящее правило ссылки, иначе вариант с учётом весов,
+// вариант закрепляется за посетителем cookie. В адрес подставляются шаблоны, UTM метки и, если включено,
+// параметры запроса короткой ссылки.
+func New(log *slog.Logger, svc URLService, clicks ClickRecorder) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "GetID.Get"
 
@@ -33,21 +49,96 @@ func New(log *slog.Logger, svc URLService) http.HandlerFunc {
 			return
 		}
 
+		shortCode, preview := previewRequested(r, shortCode)
+		if shortCode == "" {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+
 		url, err := svc.GetID(r.Context(), shortCode)
 		if err != nil {
 			log.Error("service GetID", "error", err)
 			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 			return
 		}
-		w.Header().Set("OriginalURL", url.OriginalURL)
-
+		audit := model.AuditFromContext(r.Context())
 		if url.DeletedFlag {
+			audit.OriginalURL = linkURL(url)
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+
+		if r.Method != http.MethodPost && (preview || url.PreviewRequired && !confirmed(r)) {
+			audit.OriginalURL = linkURL(url)
+			showPreview(w, r, log, svc, url)
+			return
+		}
 
+		if url.IsExpired(time.Now()) {
+			audit.Action = model.ActionExpired
+			audit.OriginalURL = linkURL(url)
 			w.WriteHeader(http.StatusGone)
 			return
 		}
 
-		w.Header().Set("Location", url.OriginalURL)
+		target, variant := chooseTarget(r, url)
+		audit.Variant = variant
+		location := redirectURL(target, url, r.URL.RawQuery, time.Now())
+		if err := svc.CheckTarget(r.Context(), location); err != nil {
+			if !errors.Is(err, model.ErrURLBlocked) {
+				log.Error("service CheckTarget", "error", err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			audit.Action = model.ActionBlocked
+			if !url.IsProtected() {
+				audit.OriginalURL = target
+			}
+			blocked(w, r, err)
+			return
+		}
+
+		if url.IsProtected() {
+			if !unlock(w, r, log, svc, url) {
+				return
+			}
+		}
+		// адрес попадает в аудит только после проверки и ввода пароля
+		audit.OriginalURL = target
+
+		if url.MaxClicks > 0 {
+			ok, err := svc.ConsumeClick(r.Context(), url.UUID)
+			if err != nil {
+				log.Error("service ConsumeClick", "error", err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			if !ok {
+				audit.Action = model.ActionClickLimit
+				w.WriteHeader(http.StatusGone)
+				return
+			}
+		}
+
+		clicks.AddClick(newClick(r, url.UUID))
+		if variant != "" {
+			http.SetCookie(w, variantCookie(url.UUID, variant))
+		}
+		w.Header().Set("Location", location)
+		if r.Method == http.MethodPost {
+			// после отправки формы 307 повторил бы POST с паролем на чужой сайт
+			audit.Action = model.ActionFollow
+			w.WriteHeader(http.StatusSeeOther)
+			return
+		}
 		w.WriteHeader(http.StatusTemporaryRedirect)
 	}
 }
+
+// linkURL адрес ссылки для аудита запросов без перехода. Адрес защищённой ссылки не раскрывается
+func linkURL(url *model.URL) string {
+	if url.IsProtected() {
+		return ""
+	}
+	return url.OriginalURL
+}
@@ -2,12 +2,15 @@ package getid
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ArtShib/urlshortener/internal/model"
 	"github.com/go-chi/chi/v5"
@@ -28,6 +31,29 @@ func (m *MockURLService) GetID(ctx context.Context, shortCode string) (*model.UR
 	return args.Get(0).(*model.URL), args.Error(1)
 }
 
+func (m *MockURLService) ConsumeClick(ctx context.Context, shortCode string) (bool, error) {
+	args := m.Called(ctx, shortCode)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockURLService) CheckTarget(ctx context.Context, originalURL string) error {
+	args := m.Called(ctx, originalURL)
+	return args.Error(0)
+}
+
+func (m *MockURLService) Unlock(ctx context.Context, url *model.URL, password string) error {
+	args := m.Called(ctx, url, password)
+	return args.Error(0)
+}
+
+type clickSpy struct {
+	clicks []model.Click
+}
+
+func (s *clickSpy) AddClick(click model.Click) {
+	s.clicks = append(s.clicks, click)
+}
+
 func withURLParam(r *http.Request, key, value string) *http.Request {
 	ctx := chi.NewRouteContext()
 	ctx.URLParams.Add(key, value)
@@ -43,6 +69,7 @@ func TestGetIDHandler(t *testing.T) {
 		mockFunc         func(m *MockURLService, shortCode string)
 		expectedStatus   int
 		expectedLocation string
+		expectedAuditURL string
 	}{
 		{
 			name:       "Success",
@@ -54,6 +81,7 @@ func TestGetIDHandler(t *testing.T) {
 			},
 			expectedStatus:   http.StatusTemporaryRedirect,
 			expectedLocation: "https://google.com",
+			expectedAuditURL: "https://google.com",
 		},
 		{
 			name:       "Gone",
@@ -65,6 +93,61 @@ func TestGetIDHandler(t *testing.T) {
 			},
 			expectedStatus:   http.StatusGone,
 			expectedLocation: "",
+			expectedAuditURL: "https://google.com",
+		},
+		{
+			name:       "Expired",
+			urlParamID: "sdsd34vcx",
+			mockFunc: func(m *MockURLService, shortCode string) {
+				expiresAt := time.Now().Add(-time.Hour)
+				m.On("GetID", mock.Anything, shortCode).
+					Return(&model.URL{OriginalURL: "https://google.com", ExpiresAt: &expiresAt}, nil).
+					Once()
+			},
+			expectedStatus:   http.StatusGone,
+			expectedLocation: "",
+			expectedAuditURL: "https://google.com",
+		},
+		{
+			name:       "ClickAllowed",
+			urlParamID: "sdsd34vcx",
+			mockFunc: func(m *MockURLService, shortCode string) {
+				m.On("GetID", mock.Anything, shortCode).
+					Return(&model.URL{UUID: shortCode, OriginalURL: "https://google.com", MaxClicks: 3}, nil).
+					Once()
+				m.On("ConsumeClick", mock.Anything, shortCode).Return(true, nil).Once()
+			},
+			expectedStatus:   http.StatusTemporaryRedirect,
+			expectedLocation: "https://google.com",
+			expectedAuditURL: "https://google.com",
+		},
+		{
+			name:       "ClickLimitReached",
+			urlParamID: "sdsd34vcx",
+			mockFunc: func(m *MockURLService, shortCode string) {
+				m.On("GetID", mock.Anything, shortCode).
+					Return(&model.URL{UUID: shortCode, OriginalURL: "https://google.com", MaxClicks: 3, Clicks: 3}, nil).
+					Once()
+				m.On("ConsumeClick", mock.Anything, shortCode).Return(false, nil).Once()
+			},
+			expectedStatus:   http.StatusGone,
+			expectedLocation: "",
+			expectedAuditURL: "https://google.com",
+		},
+		{
+			name:       "Blocked",
+			urlParamID: "sdsd34vcx",
+			mockFunc: func(m *MockURLService, shortCode string) {
+				m.On("GetID", mock.Anything, shortCode).
+					Return(&model.URL{OriginalURL: "https://evil.com"}, nil).
+					Once()
+				m.On("CheckTarget", mock.Anything, "https://evil.com").
+					Return(model.NewRequestError(model.ErrURLBlocked, "domain evil.com is blocked")).
+					Once()
+			},
+			expectedStatus:   http.StatusForbidden,
+			expectedLocation: "",
+			expectedAuditURL: "https://evil.com",
 		},
 		{
 			name:             "EmptyID",
@@ -90,11 +173,16 @@ func TestGetIDHandler(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			svc := new(MockURLService)
 			test.mockFunc(svc, test.urlParamID)
+			svc.On("CheckTarget", mock.Anything, mock.Anything).Return(nil).Maybe()
 
-			handler := New(logger, svc)
+			clicks := &clickSpy{}
+			handler := New(logger, svc, clicks)
 
 			req := httptest.NewRequest(http.MethodGet, "/{id}", nil)
+			req.Header.Set("Referer", "https://News.example.com/post/1")
 			req = withURLParam(req, "shortCode", test.urlParamID)
+			ctx, audit := model.NewAuditContext(req.Context())
+			req = req.WithContext(ctx)
 
 			w := httptest.NewRecorder()
 
@@ -108,12 +196,486 @@ func TestGetIDHandler(t *testing.T) {
 			}()
 
 			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+			assert.Equal(t, test.expectedAuditURL, audit.OriginalURL)
+			assert.Empty(t, resp.Header.Get("OriginalURL"), "target is not sent in a header")
 			if test.expectedLocation != "" {
 				assert.Equal(t, test.expectedLocation, resp.Header.Get("Location"))
+				require.Len(t, clicks.clicks, 1, "redirect must be recorded")
+				assert.Equal(t, "news.example.com", clicks.clicks[0].Referrer)
 			} else {
 				assert.Empty(t, resp.Header.Get("Location"))
+				assert.Empty(t, clicks.clicks, "only redirects are recorded")
+			}
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetIDHandler_Password(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	protected := &model.URL{UUID: "sdsd34vcx", OriginalURL: "https://google.com", PasswordHash: "hash"}
+
+	tests := []struct {
+		name             string
+		method           string
+		header           http.Header
+		form             string
+		mockFunc         func(m *MockURLService)
+		expectedStatus   int
+		expectedLocation string
+		expectedAction   string
+		expectedBody     string
+	}{
+		{
+			name:           "FormForBrowser",
+			method:         http.MethodGet,
+			header:         http.Header{"Accept": {"text/html"}},
+			mockFunc:       func(m *MockURLService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedAction: model.ActionPasswordRequired,
+			expectedBody:   `name="password"`,
+		},
+		{
+			name:   "HeaderPassword",
+			method: http.MethodGet,
+			header: http.Header{model.PasswordHeader: {"secret"}},
+			mockFunc: func(m *MockURLService) {
+				m.On("Unlock", mock.Anything, protected, "secret").Return(nil).Once()
+			},
+			expectedStatus:   http.StatusTemporaryRedirect,
+			expectedLocation: "https://google.com",
+		},
+		{
+			name:   "WrongHeaderPassword",
+			method: http.MethodGet,
+			header: http.Header{model.PasswordHeader: {"guess"}},
+			mockFunc: func(m *MockURLService) {
+				m.On("Unlock", mock.Anything, protected, "guess").Return(model.ErrWrongPassword).Once()
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedAction: model.ActionPasswordFailed,
+		},
+		{
+			name:   "FormPassword",
+			method: http.MethodPost,
+			header: http.Header{"Content-Type": {"application/x-www-form-urlencoded"}},
+			form:   "password=secret",
+			mockFunc: func(m *MockURLService) {
+				m.On("Unlock", mock.Anything, protected, "secret").Return(nil).Once()
+			},
+			expectedStatus:   http.StatusSeeOther,
+			expectedLocation: "https://google.com",
+			expectedAction:   model.ActionFollow,
+		},
+		{
+			name:   "TooManyAttempts",
+			method: http.MethodPost,
+			header: http.Header{"Content-Type": {"application/x-www-form-urlencoded"}, "Accept": {"text/html"}},
+			form:   "password=guess",
+			mockFunc: func(m *MockURLService) {
+				m.On("Unlock", mock.Anything, protected, "guess").Return(model.ErrTooManyAttempts).Once()
+			},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedAction: model.ActionPasswordFailed,
+			expectedBody:   "Too many attempts",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc := new(MockURLService)
+			svc.On("GetID", mock.Anything, protected.UUID).Return(protected, nil).Once()
+			test.mockFunc(svc)
+			svc.On("CheckTarget", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+			clicks := &clickSpy{}
+			handler := New(logger, svc, clicks)
+
+			req := httptest.NewRequest(test.method, "/{id}", strings.NewReader(test.form))
+			for key, values := range test.header {
+				req.Header[key] = values
+			}
+			req = withURLParam(req, "shortCode", protected.UUID)
+			ctx, audit := model.NewAuditContext(req.Context())
+			req = req.WithContext(ctx)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+			assert.Equal(t, test.expectedLocation, resp.Header.Get("Location"))
+			assert.Equal(t, test.expectedAction, audit.Action)
+			assert.Empty(t, resp.Header.Get("AuditAction"), "audit details are not sent to the client")
+			assert.Contains(t, string(body), test.expectedBody)
+			assert.Equal(t, test.expectedLocation, audit.OriginalURL, "target is audited only after unlock")
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetIDHandler_Preview(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	createdAt := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+	link := &model.URL{UUID: "sdsd34vcx", ShortURL: "http://localhost:8080/sdsd34vcx", OriginalURL: "https://google.com",
+		Title: "Search", CreatedAt: createdAt}
+
+	tests := []struct {
+		name             string
+		urlParamID       string
+		target           string
+		header           http.Header
+		url              *model.URL
+		checkErr         error
+		expectedStatus   int
+		expectedLocation string
+		expected         *model.LinkPreview
+		expectedBody     string
+	}{
+		{
+			name:           "SuffixJSON",
+			urlParamID:     "sdsd34vcx+",
+			target:         "/sdsd34vcx+",
+			url:            link,
+			expectedStatus: http.StatusOK,
+			expected: &model.LinkPreview{ShortURL: link.ShortURL, OriginalURL: "https://google.com", Title: "Search",
+				CreatedAt: createdAt, Status: model.PreviewStatusSafe, ContinueURL: link.ShortURL + "?confirm=1"},
+		},
+		{
+			name:           "QueryHTML",
+			urlParamID:     "sdsd34vcx",
+			target:         "/sdsd34vcx?preview=1",
+			header:         http.Header{"Accept": {"text/html"}},
+			url:            link,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "<code>https://google.com</code>",
+		},
+		{
+			name:           "Blocked",
+			urlParamID:     "sdsd34vcx+",
+			target:         "/sdsd34vcx+",
+			url:            &model.URL{UUID: "sdsd34vcx", ShortURL: link.ShortURL, OriginalURL: "https://evil.com"},
+			checkErr:       model.NewRequestError(model.ErrURLBlocked, "domain evil.com is blocked"),
+			expectedStatus: http.StatusOK,
+			expected: &model.LinkPreview{ShortURL: link.ShortURL, OriginalURL: "https://evil.com",
+				Status: model.PreviewStatusBlocked, Reason: "domain evil.com is blocked"},
+		},
+		{
+			name:           "ProtectedHidesTarget",
+			urlParamID:     "sdsd34vcx+",
+			target:         "/sdsd34vcx+",
+			url:            &model.URL{UUID: "sdsd34vcx", ShortURL: link.ShortURL, OriginalURL: "https://google.com", PasswordHash: "hash"},
+			expectedStatus: http.StatusOK,
+			expected: &model.LinkPreview{ShortURL: link.ShortURL, Status: model.PreviewStatusSafe, Protected: true,
+				ContinueURL: link.ShortURL + "?confirm=1"},
+		},
+		{
+			name:           "Required",
+			urlParamID:     "sdsd34vcx",
+			target:         "/sdsd34vcx",
+			url:            &model.URL{UUID: "sdsd34vcx", ShortURL: link.ShortURL, OriginalURL: "https://google.com", PreviewRequired: true},
+			expectedStatus: http.StatusOK,
+			expected: &model.LinkPreview{ShortURL: link.ShortURL, OriginalURL: "https://google.com", Status: model.PreviewStatusSafe,
+				PreviewRequired: true, ContinueURL: link.ShortURL + "?confirm=1"},
+		},
+		{
+			name:             "RequiredConfirmed",
+			urlParamID:       "sdsd34vcx",
+			target:           "/sdsd34vcx?confirm=1",
+			url:              &model.URL{UUID: "sdsd34vcx", ShortURL: link.ShortURL, OriginalURL: "https://google.com", PreviewRequired: true},
+			expectedStatus:   http.StatusTemporaryRedirect,
+			expectedLocation: "https://google.com",
+		},
+		{
+			name:           "Deleted",
+			urlParamID:     "sdsd34vcx+",
+			target:         "/sdsd34vcx+",
+			url:            &model.URL{UUID: "sdsd34vcx", OriginalURL: "https://google.com", DeletedFlag: true},
+			expectedStatus: http.StatusGone,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc := new(MockURLService)
+			svc.On("GetID", mock.Anything, "sdsd34vcx").Return(test.url, nil).Once()
+			svc.On("CheckTarget", mock.Anything, test.url.OriginalURL).Return(test.checkErr).Maybe()
+
+			clicks := &clickSpy{}
+			handler := New(logger, svc, clicks)
+
+			req := httptest.NewRequest(http.MethodGet, test.target, nil)
+			for key, values := range test.header {
+				req.Header[key] = values
+			}
+			req = withURLParam(req, "shortCode", test.urlParamID)
+			ctx, audit := model.NewAuditContext(req.Context())
+			req = req.WithContext(ctx)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+			assert.Equal(t, test.expectedLocation, resp.Header.Get("Location"))
+			if test.expectedLocation != "" {
+				assert.Len(t, clicks.clicks, 1)
+				return
+			}
+			assert.Empty(t, clicks.clicks, "preview is not a click")
+			if test.expectedStatus != http.StatusOK {
+				return
+			}
+			assert.Equal(t, model.ActionPreview, audit.Action)
+			assert.Contains(t, string(body), test.expectedBody)
+			if test.expected != nil {
+				var preview model.LinkPreview
+				require.NoError(t, json.Unmarshal(body, &preview))
+				assert.Equal(t, *test.expected, preview)
+			}
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetIDHandler_Variants(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	split := &model.URL{UUID: "sdsd34vcx", OriginalURL: "https://example.com/a", Targets: []model.Target{
+		{Name: "a", URL: "https://example.com/a", Weight: 70},
+		{Name: "b", URL: "https://example.com/b", Weight: 30},
+	}}
+
+	tests := []struct {
+		name             string
+		cookie           string
+		expectedLocation []string
+		expectedVariant  []string
+	}{
+		{name: "StickyCookie", cookie: "b", expectedLocation: []string{"https://example.com/b"}, expectedVariant: []string{"b"}},
+		{name: "NewVisitor", expectedLocation: []string{"https://example.com/a", "https://example.com/b"}, expectedVariant: []string{"a", "b"}},
+		{name: "UnknownVariant", cookie: "c", expectedLocation: []string{"https://example.com/a", "https://example.com/b"}, expectedVariant: []string{"a", "b"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc := new(MockURLService)
+			svc.On("GetID", mock.Anything, split.UUID).Return(split, nil).Once()
+			svc.On("CheckTarget", mock.Anything, mock.Anything).Return(nil).Once()
+
+			clicks := &clickSpy{}
+			handler := New(logger, svc, clicks)
+
+			req := httptest.NewRequest(http.MethodGet, "/"+split.UUID, nil)
+			if test.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: model.VariantCookie, Value: test.cookie})
+			}
+			req = withURLParam(req, "shortCode", split.UUID)
+			ctx, audit := model.NewAuditContext(req.Context())
+			req = req.WithContext(ctx)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			resp := w.Result()
+			require.NoError(t, resp.Body.Close())
+
+			assert.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+			location := resp.Header.Get("Location")
+			assert.Contains(t, test.expectedLocation, location)
+			variant := audit.Variant
+			assert.Contains(t, test.expectedVariant, variant)
+			assert.Empty(t, resp.Header.Get("AuditVariant"), "variant reaches the audit without a header")
+			assert.Equal(t, location, audit.OriginalURL, "audit records the chosen target")
+
+			cookies := resp.Cookies()
+			require.Len(t, cookies, 1)
+			assert.Equal(t, model.VariantCookie, cookies[0].Name)
+			assert.Equal(t, variant, cookies[0].Value)
+			assert.Equal(t, "/"+split.UUID, cookies[0].Path)
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPickVariant(t *testing.T) {
+	url := &model.URL{Targets: []model.Target{
+		{Name: "a", Weight: 70},
+		{Name: "b", Weight: 30},
+	}}
+	require.Equal(t, 100, url.TotalWeight())
+
+	counts := map[string]int{}
+	for n := range url.TotalWeight() {
+		counts[url.PickVariant(n).Name]++
+	}
+	assert.Equal(t, map[string]int{"a": 70, "b": 30}, counts)
+}
+
+func TestGetIDHandler_Rules(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	link := &model.URL{UUID: "sdsd34vcx", OriginalURL: "https://example.com", Rules: []model.Rule{
+		{URL: "https://apps.apple.com/app/id1", Device: "ios"},
+		{URL: "https://play.google.com/store/apps/details?id=app", Device: "android"},
+		{URL: "https://example.com/de", Language: "de"},
+		{URL: "https://example.com/promo", Query: map[string]string{"ref": "promo"}},
+	}}
+	const (
+		iphone  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148"
+		android = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 Chrome/120.0 Mobile Safari/537.36"
+		desktop = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36"
+	)
+
+	tests := []struct {
+		name             string
+		target           string
+		header           http.Header
+		expectedLocation string
+	}{
+		{name: "IOS", target: "/sdsd34vcx", header: http.Header{"User-Agent": {iphone}, "Accept-Language": {"de-DE"}}, expectedLocation: "https://apps.apple.com/app/id1"},
+		{name: "Android", target: "/sdsd34vcx", header: http.Header{"User-Agent": {android}}, expectedLocation: "https://play.google.com/store/apps/details?id=app"},
+		{name: "Language", target: "/sdsd34vcx", header: http.Header{"User-Agent": {desktop}, "Accept-Language": {"en;q=0.5, de-AT"}}, expectedLocation: "https://example.com/de"},
+		{name: "Query", target: "/sdsd34vcx?ref=promo", header: http.Header{"User-Agent": {desktop}}, expectedLocation: "https://example.com/promo"},
+		{name: "Fallback", target: "/sdsd34vcx?ref=other", header: http.Header{"User-Agent": {desktop}, "Accept-Language": {"en-US,de;q=0.8"}}, expectedLocation: "https://example.com"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc := new(MockURLService)
+			svc.On("GetID", mock.Anything, link.UUID).Return(link, nil).Once()
+			svc.On("CheckTarget", mock.Anything, test.expectedLocation).Return(nil).Once()
+
+			handler := New(logger, svc, &clickSpy{})
+
+			req := httptest.NewRequest(http.MethodGet, test.target, nil)
+			for key, values := range test.header {
+				req.Header[key] = values
 			}
+			req = withURLParam(req, "shortCode", link.UUID)
+			ctx, audit := model.NewAuditContext(req.Context())
+			req = req.WithContext(ctx)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			resp := w.Result()
+			require.NoError(t, resp.Body.Close())
+
+			assert.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+			assert.Equal(t, test.expectedLocation, resp.Header.Get("Location"))
+			assert.Equal(t, test.expectedLocation, audit.OriginalURL)
 			svc.AssertExpectations(t)
 		})
 	}
 }
+
+func TestInWindow(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, 5, 1, hour, minute, 0, 0, time.UTC)
+	}
+	day := model.Rule{From: "09:00", To: "18:00"}
+	night := model.Rule{From: "22:00", To: "06:00"}
+
+	assert.True(t, inWindow(day, at(9, 0)))
+	assert.True(t, inWindow(day, at(17, 59)))
+	assert.False(t, inWindow(day, at(18, 0)))
+	assert.True(t, inWindow(night, at(23, 30)))
+	assert.True(t, inWindow(night, at(5, 0)))
+	assert.False(t, inWindow(night, at(12, 0)))
+	assert.False(t, inWindow(model.Rule{From: "09:00", To: "18:00", Timezone: "Nowhere/Nothing"}, at(12, 0)))
+}
+
+func TestPreferredLanguage(t *testing.T) {
+	assert.Equal(t, "", preferredLanguage(""))
+	assert.Equal(t, "fr-ch", preferredLanguage("fr-CH, fr;q=0.9, en;q=0.8, *;q=0.5"))
+	assert.Equal(t, "de", preferredLanguage("en;q=0.5, de"))
+	assert.Equal(t, "en", preferredLanguage("*, en;q=0.1"))
+}
+
+func TestRedirectURL(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name     string
+		target   string
+		url      model.URL
+		rawQuery string
+		want     string
+	}{
+		{
+			name:     "Verbatim",
+			target:   "https://example.com/p?a=1",
+			rawQuery: "b=2",
+			want:     "https://example.com/p?a=1",
+		},
+		{
+			name:   "Placeholders",
+			target: "https://example.com/%7Bcode%7D/x?c={code}&t={ts}",
+			want:   "https://example.com/abc/x?c=abc&t=1700000000",
+		},
+		{
+			name:     "Passthrough",
+			target:   "https://example.com/p?a=1",
+			url:      model.URL{Passthrough: true},
+			rawQuery: "b=2&a=9&b=3&x=a%26b%20c&preview=1&confirm=1",
+			want:     "https://example.com/p?a=1&b=2&b=3&x=a%26b%20c",
+		},
+		{
+			name:     "PassthroughSkipsMalformed",
+			target:   "https://example.com/",
+			url:      model.URL{Passthrough: true},
+			rawQuery: "bad=%zz&&k;v=1&ok=1",
+			want:     "https://example.com/?ok=1",
+		},
+		{
+			name:     "UTMPrecedence",
+			target:   "https://example.com/?utm_source=site",
+			url:      model.URL{Passthrough: true, UTM: &model.UTM{Source: "news", Medium: "email", Campaign: "spring sale", Content: "{code}"}},
+			rawQuery: "utm_medium=ad&ref=tw",
+			want:     "https://example.com/?utm_source=site&utm_medium=email&utm_campaign=spring+sale&utm_content=abc&ref=tw",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.url.UUID = "abc"
+			assert.Equal(t, test.want, redirectURL(test.target, &test.url, test.rawQuery, now))
+		})
+	}
+}
+
+func TestGetIDHandler_Passthrough(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	link := &model.URL{UUID: "sdsd34vcx", OriginalURL: "https://example.com/landing?lang=en", Passthrough: true,
+		UTM: &model.UTM{Source: "short", Content: "{code}"}}
+	const location = "https://example.com/landing?lang=en&utm_source=short&utm_content=sdsd34vcx&gclid=a%2Bb"
+
+	svc := new(MockURLService)
+	svc.On("GetID", mock.Anything, link.UUID).Return(link, nil).Once()
+	svc.On("CheckTarget", mock.Anything, location).Return(nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/sdsd34vcx?gclid=a%2Bb&utm_source=ads&lang=de", nil)
+	req = withURLParam(req, "shortCode", link.UUID)
+	ctx, audit := model.NewAuditContext(req.Context())
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+	New(logger, svc, &clickSpy{}).ServeHTTP(w, req)
+
+	resp := w.Result()
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+	assert.Equal(t, location, resp.Header.Get("Location"), "link params and utm win over the short link query")
+	assert.Equal(t, link.OriginalURL, audit.OriginalURL)
+	svc.AssertExpectations(t)
+}
+
+func TestContinueQuery(t *testing.T) {
+	assert.Equal(t, "confirm=1", continueQuery(""))
+	assert.Equal(t, "ref=tw&q=a%20b&confirm=1", continueQuery("preview=1&ref=tw&q=a%20b&confirm=0"))
+}
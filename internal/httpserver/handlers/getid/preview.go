@@ -0,0 +1,120 @@
+package getid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// previewPage страница предпросмотра ссылки
+var previewPage = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<title>{{if .Title}}{{.Title}}{{else}}Link preview{{end}}</title>
+</head>
+<body>
+<h1>{{if .Title}}{{.Title}}{{else}}Link preview{{end}}</h1>
+<dl>
+<dt>Short link</dt>
+<dd>{{.ShortURL}}</dd>
+<dt>Destination</dt>
+<dd>{{if .Protected}}Hidden, the link is protected by a password{{else}}<code>{{.OriginalURL}}</code>{{end}}</dd>
+{{if not .CreatedAt.IsZero}}<dt>Created</dt>
+<dd><time datetime="{{.CreatedAt.Format "2006-01-02T15:04:05Z07:00"}}">{{.CreatedAt.Format "January 2, 2006"}}</time></dd>
+{{end}}<dt>Status</dt>
+<dd>{{if eq .Status "safe"}}No known issues{{else if eq .Status "blocked"}}Flagged as unsafe{{if .Reason}}: {{.Reason}}{{end}}{{else if eq .Status "expired"}}Expired{{else}}Click limit reached{{end}}</dd>
+</dl>
+{{if .ContinueURL}}<p><a href="{{.ContinueURL}}" rel="nofollow noreferrer">Continue</a></p>{{end}}
+</body>
+</html>
+`))
+
+// previewRequested отделяет признак предпросмотра от короткого кода: code+ или ?preview=1
+func previewRequested(r *http.Request, shortCode string) (string, bool) {
+	if code, ok := strings.CutSuffix(shortCode, model.PreviewSuffix); ok {
+		return code, true
+	}
+	preview, _ := strconv.ParseBool(r.URL.Query().Get("preview"))
+	return shortCode, preview
+}
+
+// confirmed проверяет, что посетитель уже прошёл страницу предпросмотра
+func confirmed(r *http.Request) bool {
+	ok, _ := strconv.ParseBool(r.URL.Query().Get("confirm"))
+	return ok
+}
+
+// newPreview собирает сведения о ссылке и проверяет состояние адреса назначения
+func newPreview(ctx context.Context, svc URLService, url *model.URL, rawQuery string) (model.LinkPreview, error) {
+	preview := model.LinkPreview{
+		ShortURL:        url.ShortURL,
+		Title:           url.Title,
+		CreatedAt:       url.CreatedAt,
+		Status:          model.PreviewStatusSafe,
+		Protected:       url.IsProtected(),
+		PreviewRequired: url.PreviewRequired,
+	}
+	if !preview.Protected {
+		preview.OriginalURL = url.OriginalURL
+	}
+
+	switch {
+	case url.IsExpired(time.Now()):
+		preview.Status = model.PreviewStatusExpired
+	case url.MaxClicks > 0 && url.Clicks >= url.MaxClicks:
+		preview.Status = model.PreviewStatusClickLimit
+	default:
+		if err := svc.CheckTarget(ctx, url.OriginalURL); err != nil {
+			if !errors.Is(err, model.ErrURLBlocked) {
+				return preview, err
+			}
+			preview.Status = model.PreviewStatusBlocked
+			var reqErr *model.RequestError
+			// причина блокировки может содержать адрес защищённой ссылки
+			if errors.As(err, &reqErr) && !preview.Protected {
+				preview.Reason = reqErr.Reason
+			}
+		}
+	}
+	if preview.Status == model.PreviewStatusSafe && url.ShortURL != "" {
+		preview.ContinueURL = url.ShortURL + "?" + continueQuery(rawQuery)
+	}
+	return preview, nil
+}
+
+// showPreview отвечает страницей предпросмотра браузерам и JSON остальным клиентам.
+// Переход при этом не засчитывается
+func showPreview(w http.ResponseWriter, r *http.Request, log *slog.Logger, svc URLService, url *model.URL) {
+	preview, err := newPreview(r.Context(), svc, url, r.URL.RawQuery)
+	if err != nil {
+		log.Error("service CheckTarget", "error", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	model.AuditFromContext(r.Context()).Action = model.ActionPreview
+	w.Header().Set("Cache-Control", "no-store")
+	if !acceptsHTML(r) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(preview); err != nil {
+			log.Error("Encode response", "error", err)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if err := previewPage.Execute(w, preview); err != nil {
+		log.Error("Execute template", "error", err)
+	}
+}
@@ -0,0 +1,106 @@
+package getid
+
+import (
+	neturl "net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// controlParams параметры самого сервиса, они не переносятся в адрес перехода
+var controlParams = []string{"preview", "confirm"}
+
+// redirectURL подставляет шаблоны {code} и {ts} в адрес перехода и UTM метки и дополняет запрос адреса.
+// Параметры, уже заданные в адресе, важнее UTM меток, а метки важнее параметров запроса короткой ссылки.
+// Параметры короткой ссылки переносятся в исходном кодировании, повторяющиеся ключи сохраняются
+func redirectURL(target string, url *model.URL, rawQuery string, now time.Time) string {
+	ts := strconv.FormatInt(now.Unix(), 10)
+	target = placeholders(neturl.QueryEscape(url.UUID), ts).Replace(target)
+	if url.UTM == nil && (!url.Passthrough || rawQuery == "") {
+		return target
+	}
+	u, err := neturl.Parse(target)
+	if err != nil {
+		return target
+	}
+
+	present := map[string]bool{}
+	for _, pair := range splitQuery(u.RawQuery) {
+		if key, ok := pairKey(pair); ok {
+			present[key] = true
+		}
+	}
+	var pairs []string
+	if url.UTM != nil {
+		values := placeholders(url.UUID, ts)
+		for _, param := range url.UTM.Params() {
+			if present[param[0]] {
+				continue
+			}
+			pairs = append(pairs, param[0]+"="+neturl.QueryEscape(values.Replace(param[1])))
+			present[param[0]] = true
+		}
+	}
+	if url.Passthrough {
+		for _, pair := range splitQuery(rawQuery) {
+			key, ok := pairKey(pair)
+			if !ok || present[key] || slices.Contains(controlParams, key) {
+				continue
+			}
+			pairs = append(pairs, pair)
+		}
+	}
+	if len(pairs) == 0 {
+		return target
+	}
+	if u.RawQuery != "" {
+		pairs = append([]string{u.RawQuery}, pairs...)
+	}
+	u.RawQuery = strings.Join(pairs, "&")
+	return u.String()
+}
+
+// placeholders заменяет шаблоны, в том числе закодированные при нормализации адреса
+func placeholders(code, ts string) *strings.Replacer {
+	return strings.NewReplacer(
+		model.PlaceholderCode, code, "%7Bcode%7D", code, "%7bcode%7d", code,
+		model.PlaceholderTS, ts, "%7Bts%7D", ts, "%7bts%7d", ts,
+	)
+}
+
+// continueQuery запрос ссылки для перехода со страницы предпросмотра: исходные параметры без служебных и confirm=1
+func continueQuery(rawQuery string) string {
+	pairs := []string{}
+	for _, pair := range splitQuery(rawQuery) {
+		if key, ok := pairKey(pair); ok && !slices.Contains(controlParams, key) {
+			pairs = append(pairs, pair)
+		}
+	}
+	return strings.Join(append(pairs, "confirm=1"), "&")
+}
+
+func splitQuery(rawQuery string) []string {
+	if rawQuery == "" {
+		return nil
+	}
+	return strings.Split(rawQuery, "&")
+}
+
+// pairKey декодирует ключ пары key=value. Пустые и некорректно закодированные пары отбрасываются
+func pairKey(pair string) (string, bool) {
+	if pair == "" || strings.Contains(pair, ";") {
+		return "", false
+	}
+	rawKey, rawValue, _ := strings.Cut(pair, "=")
+	key, err := neturl.QueryUnescape(rawKey)
+	if err != nil || key == "" {
+		return "", false
+	}
+	if _, err := neturl.QueryUnescape(rawValue); err != nil {
+		return "", false
+	}
+	return key, true
+}
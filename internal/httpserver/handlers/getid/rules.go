@@ -0,0 +1,104 @@
+package getid
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/lib/useragent"
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// visitor признаки посетителя, по которым проверяются правила перенаправления
+type visitor struct {
+	class    string
+	platform string
+	language string
+	query    map[string][]string
+	now      time.Time
+}
+
+func newVisitor(r *http.Request, now time.Time) visitor {
+	return visitor{
+		class:    useragent.Classify(r.UserAgent()),
+		platform: useragent.Platform(r.UserAgent()),
+		language: preferredLanguage(r.Header.Get("Accept-Language")),
+		query:    r.URL.Query(),
+		now:      now,
+	}
+}
+
+// matchRule ищет первое правило ссылки, все условия которого выполнены для посетителя
+func matchRule(r *http.Request, url *model.URL) (model.Rule, bool) {
+	if len(url.Rules) == 0 {
+		return model.Rule{}, false
+	}
+	v := newVisitor(r, time.Now())
+	for _, rule := range url.Rules {
+		if v.matches(rule) {
+			return rule, true
+		}
+	}
+	return model.Rule{}, false
+}
+
+func (v visitor) matches(rule model.Rule) bool {
+	if rule.Device != "" && rule.Device != v.class && rule.Device != v.platform {
+		return false
+	}
+	if rule.Language != "" && v.language != rule.Language && !strings.HasPrefix(v.language, rule.Language+"-") {
+		return false
+	}
+	for name, value := range rule.Query {
+		values, ok := v.query[name]
+		if !ok || value != "" && values[0] != value {
+			return false
+		}
+	}
+	if rule.From != "" && !inWindow(rule, v.now) {
+		return false
+	}
+	return true
+}
+
+// inWindow проверяет, что время now в зоне правила попадает в [From, To). Окно с From позже To переходит через полночь
+func inWindow(rule model.Rule, now time.Time) bool {
+	from, errFrom := time.Parse(model.RuleTimeLayout, rule.From)
+	to, errTo := time.Parse(model.RuleTimeLayout, rule.To)
+	loc, errLoc := time.LoadLocation(rule.Timezone)
+	if errFrom != nil || errTo != nil || errLoc != nil {
+		return false
+	}
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	start, end := from.Hour()*60+from.Minute(), to.Hour()*60+to.Minute()
+	if start < end {
+		return start <= minute && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// preferredLanguage возвращает язык с наибольшим весом q из Accept-Language в нижнем регистре
+func preferredLanguage(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if raw, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > bestQ {
+			best, bestQ = tag, q
+		}
+	}
+	return best
+}
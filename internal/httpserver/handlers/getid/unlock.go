@@ -0,0 +1,88 @@
+package getid
+
+import (
+	"errors"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// unlockPage форма ввода пароля защищённой ссылки
+var unlockPage = template.Must(template.New("unlock").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<title>Password required</title>
+</head>
+<body>
+<form method="post">
+<p>This link is protected by a password.</p>
+{{if .}}<p role="alert">{{.}}</p>{{end}}
+<input type="password" name="password" autofocus required>
+<button type="submit">Open</button>
+</form>
+</body>
+</html>
+`))
+
+// unlock проверяет пароль защищённой ссылки из заголовка или из формы.
+// Возвращает false, если ответ клиенту уже записан
+func unlock(w http.ResponseWriter, r *http.Request, log *slog.Logger, svc URLService, url *model.URL) bool {
+	plain, ok := passwordFromRequest(r)
+	if !ok {
+		model.AuditFromContext(r.Context()).Action = model.ActionPasswordRequired
+		denyAccess(w, r, http.StatusUnauthorized, "")
+		return false
+	}
+
+	err := svc.Unlock(r.Context(), url, plain)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, model.ErrTooManyAttempts):
+		model.AuditFromContext(r.Context()).Action = model.ActionPasswordFailed
+		denyAccess(w, r, http.StatusTooManyRequests, "Too many attempts, try again later.")
+	case errors.Is(err, model.ErrWrongPassword):
+		model.AuditFromContext(r.Context()).Action = model.ActionPasswordFailed
+		denyAccess(w, r, http.StatusUnauthorized, "Wrong password.")
+	default:
+		log.Error("service Unlock", "error", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+	return false
+}
+
+// passwordFromRequest достаёт пароль из заголовка (JSON клиенты) или из отправленной формы
+func passwordFromRequest(r *http.Request) (string, bool) {
+	if plain := r.Header.Get(model.PasswordHeader); plain != "" {
+		return plain, true
+	}
+	if r.Method != http.MethodPost {
+		return "", false
+	}
+	plain := r.PostFormValue("password")
+	return plain, plain != ""
+}
+
+// denyAccess отвечает формой ввода пароля браузерам и текстом остальным клиентам
+func denyAccess(w http.ResponseWriter, r *http.Request, status int, message string) {
+	w.Header().Set("Cache-Control", "no-store")
+	if !acceptsHTML(r) {
+		if message == "" {
+			message = "Password required."
+		}
+		http.Error(w, message, status)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_ = unlockPage.Execute(w, message)
+}
+
+func acceptsHTML(r *http.Request) bool {
+	return r.Header.Get(model.PasswordHeader) == "" && strings.Contains(r.Header.Get("Accept"), "text/html")
+}
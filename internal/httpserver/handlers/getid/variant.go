@@ -0,0 +1,44 @@
+package getid
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// variantMaxAge срок, на который за посетителем закрепляется вариант ссылки
+const variantMaxAge = 30 * 24 * time.Hour
+
+// chooseTarget выбирает адрес перехода и имя варианта. Подходящее правило перенаправления важнее вариантов,
+// для ссылки с вариантами посетитель получает вариант из cookie, а при первом переходе случайный с учётом весов
+func chooseTarget(r *http.Request, url *model.URL) (string, string) {
+	if rule, ok := matchRule(r, url); ok {
+		return rule.URL, ""
+	}
+	total := url.TotalWeight()
+	if !url.IsSplit() || total <= 0 {
+		return url.OriginalURL, ""
+	}
+	if cookie, err := r.Cookie(model.VariantCookie); err == nil {
+		if target, ok := url.Variant(cookie.Value); ok {
+			return target.URL, target.Name
+		}
+	}
+	target := url.PickVariant(rand.IntN(total))
+	return target.URL, target.Name
+}
+
+// variantCookie закрепляет вариант за посетителем. Путь cookie ограничен короткой ссылкой,
+// поэтому у каждой ссылки свой вариант
+func variantCookie(shortCode, variant string) *http.Cookie {
+	return &http.Cookie{
+		Name:     model.VariantCookie,
+		Value:    variant,
+		Path:     "/" + shortCode,
+		MaxAge:   int(variantMaxAge.Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
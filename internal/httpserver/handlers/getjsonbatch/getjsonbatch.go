@@ -5,17 +5,26 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
+	"github.com/ArtShib/urlshortener/internal/httpserver/handlers/apierror"
 	"github.com/ArtShib/urlshortener/internal/model"
 	"github.com/go-chi/chi/v5/middleware"
 )
 
 // URLService интерфейс сервиса для получния списка url созданных пользователем.
 type URLService interface {
-	GetJSONBatch(ctx context.Context, userID string) (model.URLUserBatch, error)
+	GetJSONBatch(ctx context.Context, filter model.URLUserFilter) (*model.URLUserPage, error)
 }
 
 // New конструктор HandlerFunc для получния списка url созданных пользователем.
+// Параметры: limit, cursor (X-Next-Cursor предыдущей страницы), status (all|active|deleted),
+// created_after в формате RFC 3339, q (подстрока адреса, заголовка или заметки), tag (повторяется,
+// ссылка должна иметь все метки), sort (created_desc|created_asc).
+// Ответ всегда массив ссылок. Постраничная выдача включается параметром limit или cursor,
+// курсор следующей страницы передаётся в заголовках X-Next-Cursor и Link.
 func New(log *slog.Logger, svc URLService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "GetJSONBatch.Get"
@@ -34,26 +43,80 @@ func New(log *slog.Logger, svc URLService) http.HandlerFunc {
 			return
 		}
 
-		urlsBatch, err := svc.GetJSONBatch(r.Context(), userID)
+		filter, err := parseFilter(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		filter.UserID = userID
 
+		page, err := svc.GetJSONBatch(r.Context(), filter)
+		if status, message, ok := apierror.Status(err); ok {
+			http.Error(w, message, status)
+			return
+		}
 		if err != nil {
 			log.Error("GetJSONBatch", "error", err)
 			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 			return
 		}
-		if len(urlsBatch) == 0 {
+		if len(page.URLs) == 0 && filter.Cursor == nil {
 			log.Error("StatusNoContent", "error", http.StatusText(http.StatusNoContent))
 			http.Error(w, http.StatusText(http.StatusNoContent), http.StatusNoContent)
 			return
 		}
 
+		if page.NextCursor != "" {
+			w.Header().Set(model.NextCursorHeader, page.NextCursor)
+			w.Header().Set("Link", nextLink(r.URL, page.NextCursor))
+		}
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 
 		encoder := json.NewEncoder(w)
-		if err := encoder.Encode(urlsBatch); err != nil {
+		if err := encoder.Encode(page.URLs); err != nil {
 			log.Error("Encode response", "error", err)
 			return
 		}
 	}
 }
+
+// parseFilter читает параметры выборки из query string
+func parseFilter(query url.Values) (model.URLUserFilter, error) {
+	filter := model.URLUserFilter{
+		Status: query.Get("status"),
+		Query:  query.Get("q"),
+		Sort:   query.Get("sort"),
+		Tags:   query["tag"],
+	}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return filter, model.NewRequestError(model.ErrInvalidFilter, "limit must be an integer")
+		}
+		filter.Limit = limit
+	}
+	if raw := query.Get("cursor"); raw != "" {
+		cursor, err := model.ParseURLUserCursor(raw)
+		if err != nil {
+			return filter, err
+		}
+		filter.Cursor = cursor
+	}
+	if raw := query.Get("created_after"); raw != "" {
+		createdAfter, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return filter, model.NewRequestError(model.ErrInvalidFilter, "created_after must be in RFC 3339 format")
+		}
+		filter.CreatedAfter = &createdAfter
+	}
+	return filter, nil
+}
+
+// nextLink заголовок Link со ссылкой на следующую страницу с теми же параметрами выборки
+func nextLink(current *url.URL, cursor string) string {
+	query := current.Query()
+	query.Set("cursor", cursor)
+	next := url.URL{Path: current.Path, RawQuery: query.Encode()}
+	return "<" + next.String() + `>; rel="next"`
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/ArtShib/urlshortener/internal/model"
 	"github.com/stretchr/testify/assert"
@@ -19,47 +20,122 @@ type MockURLService struct {
 	mock.Mock
 }
 
-func (m *MockURLService) GetJSONBatch(ctx context.Context, userID string) (model.URLUserBatch, error) {
-	args := m.Called(ctx, userID)
+func (m *MockURLService) GetJSONBatch(ctx context.Context, filter model.URLUserFilter) (*model.URLUserPage, error) {
+	args := m.Called(ctx, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(model.URLUserBatch), args.Error(1)
+	return args.Get(0).(*model.URLUserPage), args.Error(1)
 }
 
 func TestGetJSONBatchHandler(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	createdAfter := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cursor := model.URLUserCursor{CreatedAt: created, UUID: "asdasd"}
 
 	tests := []struct {
 		name                string
 		userID              string
+		query               string
 		mockFunc            func(m *MockURLService, userID string)
 		expectedStatus      int
 		expectedContentType string
 		expectedBody        string
 		isJSONResponse      bool
+		expectedCursor      string
+		expectedLink        string
 	}{
 		{
 			name:   "Success",
 			userID: "2",
 			mockFunc: func(m *MockURLService, userID string) {
-				m.On("GetJSONBatch", mock.Anything, userID).
-					Return(model.URLUserBatch{
-						model.URLUser{ShortURL: "http://localhost/sdfdfg", OriginalURL: "https://google.com"},
-						model.URLUser{ShortURL: "http://localhost/asdasd", OriginalURL: "https://yandex.ru"}}, nil).
+				m.On("GetJSONBatch", mock.Anything, model.URLUserFilter{UserID: userID}).
+					Return(&model.URLUserPage{URLs: model.URLUserBatch{
+						model.URLUser{UUID: "sdfdfg", ShortURL: "http://localhost/sdfdfg", OriginalURL: "https://google.com", CreatedAt: created},
+						model.URLUser{UUID: "asdasd", ShortURL: "http://localhost/asdasd", OriginalURL: "https://yandex.ru", CreatedAt: created, DeletedFlag: true}}}, nil).
 					Once()
 			},
 			expectedStatus:      http.StatusOK,
 			expectedContentType: "application/json",
-			expectedBody:        `[{"short_url":"http://localhost/sdfdfg","original_url":"https://google.com"},{"short_url":"http://localhost/asdasd","original_url":"https://yandex.ru"}]`,
+			expectedBody: `[
+				{"uuid":"sdfdfg","short_url":"http://localhost/sdfdfg","original_url":"https://google.com","created_at":"2025-01-02T03:04:05Z","is_deleted":false},
+				{"uuid":"asdasd","short_url":"http://localhost/asdasd","original_url":"https://yandex.ru","created_at":"2025-01-02T03:04:05Z","is_deleted":true}]`,
+			isJSONResponse: true,
+		},
+		{
+			name:   "FilteredPage",
+			userID: "2",
+			query:  "?limit=1&cursor=" + cursor.String() + "&status=active&created_after=2025-01-01T00:00:00Z&q=google&tag=work&tag=docs&sort=created_asc",
+			mockFunc: func(m *MockURLService, userID string) {
+				m.On("GetJSONBatch", mock.Anything, model.URLUserFilter{
+					UserID:       userID,
+					Limit:        1,
+					Cursor:       &cursor,
+					Status:       model.URLStatusActive,
+					CreatedAfter: &createdAfter,
+					Query:        "google",
+					Tags:         []string{"work", "docs"},
+					Sort:         model.SortCreatedAsc,
+				}).
+					Return(&model.URLUserPage{URLs: model.URLUserBatch{
+						model.URLUser{UUID: "sdfdfg", ShortURL: "http://localhost/sdfdfg", OriginalURL: "https://google.com", CreatedAt: created}},
+						NextCursor: "next"}, nil).
+					Once()
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody: `[
+				{"uuid":"sdfdfg","short_url":"http://localhost/sdfdfg","original_url":"https://google.com","created_at":"2025-01-02T03:04:05Z","is_deleted":false}]`,
+			isJSONResponse: true,
+			expectedCursor: "next",
+			expectedLink: `</api/user/urls?created_after=2025-01-01T00%3A00%3A00Z&cursor=next&limit=1` +
+				`&q=google&sort=created_asc&status=active&tag=work&tag=docs>; rel="next"`,
+		},
+		{
+			name:   "EmptyNextPage",
+			userID: "2",
+			query:  "?cursor=" + cursor.String(),
+			mockFunc: func(m *MockURLService, userID string) {
+				m.On("GetJSONBatch", mock.Anything, model.URLUserFilter{UserID: userID, Cursor: &cursor}).
+					Return(&model.URLUserPage{URLs: model.URLUserBatch{}}, nil).
+					Once()
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody:        `[]`,
 			isJSONResponse:      true,
 		},
+		{
+			name:                "InvalidCursor",
+			userID:              "2",
+			query:               "?cursor=!!!",
+			mockFunc:            func(m *MockURLService, userID string) {},
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        "invalid cursor: cursor is malformed\n",
+			isJSONResponse:      false,
+		},
+		{
+			name:   "InvalidLimit",
+			userID: "2",
+			query:  "?limit=5000",
+			mockFunc: func(m *MockURLService, userID string) {
+				m.On("GetJSONBatch", mock.Anything, model.URLUserFilter{UserID: userID, Limit: 5000}).
+					Return(nil, model.NewRequestError(model.ErrInvalidFilter, "limit must be between 1 and 1000")).
+					Once()
+			},
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        "invalid filter: limit must be between 1 and 1000\n",
+			isJSONResponse:      false,
+		},
 		{
 			name:   "NoContent)",
 			userID: "2",
 			mockFunc: func(m *MockURLService, userID string) {
-				m.On("GetJSONBatch", mock.Anything, userID).
-					Return(model.URLUserBatch{}, nil).
+				m.On("GetJSONBatch", mock.Anything, model.URLUserFilter{UserID: userID}).
+					Return(&model.URLUserPage{URLs: model.URLUserBatch{}}, nil).
 					Once()
 			},
 			expectedStatus:      http.StatusNoContent,
@@ -80,8 +156,8 @@ func TestGetJSONBatchHandler(t *testing.T) {
 			name:   "InternalError",
 			userID: "2",
 			mockFunc: func(m *MockURLService, userID string) {
-				m.On("GetJSONBatch", mock.Anything, userID).
-					Return(model.URLUserBatch{}, errors.New(http.StatusText(http.StatusInternalServerError))).
+				m.On("GetJSONBatch", mock.Anything, model.URLUserFilter{UserID: userID}).
+					Return(nil, errors.New(http.StatusText(http.StatusInternalServerError))).
 					Once()
 			},
 			expectedStatus:      http.StatusInternalServerError,
@@ -99,7 +175,7 @@ func TestGetJSONBatchHandler(t *testing.T) {
 
 			handler := New(logger, svc)
 
-			req := httptest.NewRequest(http.MethodGet, "/api/user/urls", nil)
+			req := httptest.NewRequest(http.MethodGet, "/api/user/urls"+test.query, nil)
 
 			if test.userID != "" {
 				ctx := context.WithValue(req.Context(), model.UserIDKey, test.userID)
@@ -122,6 +198,8 @@ func TestGetJSONBatchHandler(t *testing.T) {
 			resBody, err := io.ReadAll(resp.Body)
 			require.NoError(t, err)
 			assert.Equal(t, test.expectedContentType, resp.Header.Get("Content-Type"))
+			assert.Equal(t, test.expectedCursor, resp.Header.Get(model.NextCursorHeader))
+			assert.Equal(t, test.expectedLink, resp.Header.Get("Link"))
 			if test.isJSONResponse {
 				assert.JSONEq(t, test.expectedBody, string(resBody))
 			} else {
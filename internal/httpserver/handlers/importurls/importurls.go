@@ -0,0 +1,165 @@
+// Package importurls предоставляет загрузку ссылок пользователя из CSV или NDJSON.
+package importurls
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"slices"
+
+	"github.com/ArtShib/urlshortener/internal/httpserver/handlers/apierror"
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// chunkSize число строк, которые передаются в сервис за один вызов
+const chunkSize = 100
+
+// URLService интерфейс сервиса для импорта ссылок.
+type URLService interface {
+	ImportURLs(ctx context.Context, rows []model.ImportRow) []model.ImportResult
+}
+
+// New конструктор HandlerFunc для импорта ссылок.
+// Формат берётся из параметра format (csv|ndjson) или Content-Type (text/csv, application/x-ndjson).
+// Колонки: original_url, alias, tags, expires_at. Загрузка читается потоком и сохраняется частями,
+// ошибочная строка не прерывает импорт. В ответе отчёт по каждой строке.
+// Если загрузку не удалось дочитать, прочитанные строки всё равно сохраняются, а ответ 400
+// содержит отчёт по ним и причину в поле error. При превышении limits загрузка дальше не читается,
+// ответ 413 с отчётом по уже прочитанным строкам.
+func New(log *slog.Logger, svc URLService, limits model.ImportLimits) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "ImportURLs.Post"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		log.Info("received request")
+
+		userID, ok := r.Context().Value(model.UserIDKey).(string)
+		if !ok || userID == "" {
+			log.Error("Unauthorized", "error", http.StatusText(http.StatusUnauthorized))
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				log.Error("close body", "error", err)
+			}
+		}()
+
+		if limits.MaxBytes > 0 {
+			if r.ContentLength > limits.MaxBytes {
+				http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limits.MaxBytes)
+		}
+
+		reader, status := newReader(r)
+		if reader == nil {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+
+		report := model.ImportReport{Rows: []model.ImportResult{}}
+		status = http.StatusOK
+		chunk := make([]model.ImportRow, 0, chunkSize)
+		rows := 0
+		flush := func() {
+			if len(chunk) == 0 {
+				return
+			}
+			for _, result := range svc.ImportURLs(r.Context(), chunk) {
+				report.Add(reason(log, result))
+			}
+			chunk = chunk[:0]
+		}
+		for {
+			row, err := reader.next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			var rowErr *rowError
+			var sizeErr *http.MaxBytesError
+			switch {
+			case errors.As(err, &rowErr):
+			case errors.As(err, &sizeErr):
+				report.Error = fmt.Sprintf("upload is larger than %d bytes", sizeErr.Limit)
+				status = http.StatusRequestEntityTooLarge
+			case err != nil:
+				log.Error("read upload", "error", err)
+				report.Error = "read upload: " + err.Error()
+				status = http.StatusBadRequest
+			}
+			if report.Error != "" {
+				break
+			}
+			if rows++; limits.MaxRows > 0 && rows > limits.MaxRows {
+				report.Error = fmt.Sprintf("upload has more than %d rows", limits.MaxRows)
+				status = http.StatusRequestEntityTooLarge
+				break
+			}
+			if rowErr != nil {
+				report.Add(model.ImportResult{Line: rowErr.line, Status: model.ImportStatusError, Error: rowErr.reason})
+				continue
+			}
+			if chunk = append(chunk, row); len(chunk) == chunkSize {
+				flush()
+			}
+		}
+		flush()
+		slices.SortStableFunc(report.Rows, func(a, b model.ImportResult) int { return a.Line - b.Line })
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			log.Error("Encode response", "error", err)
+		}
+	}
+}
+
+// newReader выбирает разбор загрузки по параметру format или Content-Type.
+// При неизвестном формате возвращает nil и HTTP статус ответа
+func newReader(r *http.Request) (rowReader, int) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		switch mediaType {
+		case "", "text/csv", "text/plain":
+			format = "csv"
+		case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/json":
+			format = "ndjson"
+		default:
+			return nil, http.StatusUnsupportedMediaType
+		}
+	}
+	switch format {
+	case "csv":
+		return newCSVReader(r.Body), 0
+	case "ndjson":
+		return newNDJSONReader(r.Body), 0
+	}
+	return nil, http.StatusBadRequest
+}
+
+// reason переводит ошибку строки в текст для клиента
+func reason(log *slog.Logger, result model.ImportResult) model.ImportResult {
+	if result.Err == nil {
+		return result
+	}
+	if _, message, ok := apierror.Status(result.Err); ok {
+		result.Error = message
+		return result
+	}
+	log.Error("service ImportURLs", "line", result.Line, "error", result.Err)
+	result.Error = http.StatusText(http.StatusInternalServerError)
+	return result
+}
@@ -0,0 +1,302 @@
+package importurls
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockURLService struct {
+	mock.Mock
+}
+
+func (m *MockURLService) ImportURLs(ctx context.Context, rows []model.ImportRow) []model.ImportResult {
+	args := m.Called(ctx, rows)
+	return args.Get(0).([]model.ImportResult)
+}
+
+func TestImportURLsHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name           string
+		userID         string
+		contentType    string
+		query          string
+		body           string
+		mockFunc       func(m *MockURLService)
+		expectedStatus int
+		expectedBody   string
+		isJSONResponse bool
+	}{
+		{
+			name:        "CSVWithHeader",
+			userID:      "1",
+			contentType: "text/csv",
+			body:        "alias,original_url,tags\nsale,https://google.com,\"work, docs\"\n,https://yandex.ru,\n",
+			mockFunc: func(m *MockURLService) {
+				m.On("ImportURLs", mock.Anything, []model.ImportRow{
+					{Line: 2, OriginalURL: "https://google.com", Alias: "sale", Tags: []string{"work", "docs"}},
+					{Line: 3, OriginalURL: "https://yandex.ru"},
+				}).Return([]model.ImportResult{
+					{Line: 2, OriginalURL: "https://google.com", Status: model.ImportStatusCreated, Code: "sale", ShortURL: "http://localhost/sale"},
+					{Line: 3, OriginalURL: "https://yandex.ru", Status: model.ImportStatusExists, Code: "abc", ShortURL: "http://localhost/abc"},
+				}).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"total":2,"created":1,"exists":1,"failed":0,"rows":[
+				{"line":2,"original_url":"https://google.com","status":"created","code":"sale","short_url":"http://localhost/sale"},
+				{"line":3,"original_url":"https://yandex.ru","status":"exists","code":"abc","short_url":"http://localhost/abc"}]}`,
+			isJSONResponse: true,
+		},
+		{
+			name:   "CSVWithoutHeader",
+			userID: "1",
+			body:   "https://google.com,,,2030-01-01T00:00:00Z\n",
+			mockFunc: func(m *MockURLService) {
+				m.On("ImportURLs", mock.Anything, []model.ImportRow{
+					{Line: 1, OriginalURL: "https://google.com", ExpiresAt: "2030-01-01T00:00:00Z"},
+				}).Return([]model.ImportResult{
+					{Line: 1, OriginalURL: "https://google.com", Status: model.ImportStatusError,
+						Err: model.NewRequestError(model.ErrURLBlocked, "host is blocked")},
+				}).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"total":1,"created":0,"exists":0,"failed":1,"rows":[
+				{"line":1,"original_url":"https://google.com","status":"error","error":"url is blocked: host is blocked"}]}`,
+			isJSONResponse: true,
+		},
+		{
+			name:        "NDJSONWithBadLine",
+			userID:      "1",
+			contentType: "application/x-ndjson",
+			body:        "{\"original_url\":\"https://google.com\",\"tags\":[\"work\"]}\n\n{oops\n",
+			mockFunc: func(m *MockURLService) {
+				m.On("ImportURLs", mock.Anything, []model.ImportRow{
+					{Line: 1, OriginalURL: "https://google.com", Tags: []string{"work"}},
+				}).Return([]model.ImportResult{
+					{Line: 1, OriginalURL: "https://google.com", Status: model.ImportStatusError, Err: errors.New("database error")},
+				}).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"total":2,"created":0,"exists":0,"failed":2,"rows":[
+				{"line":1,"original_url":"https://google.com","status":"error","error":"Internal Server Error"},
+				{"line":3,"status":"error","error":"invalid json: invalid character 'o' looking for beginning of object key string"}]}`,
+			isJSONResponse: true,
+		},
+		{
+			name:           "Empty",
+			userID:         "1",
+			query:          "?format=ndjson",
+			mockFunc:       func(m *MockURLService) {},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"total":0,"created":0,"exists":0,"failed":0,"rows":[]}`,
+			isJSONResponse: true,
+		},
+		{
+			name:           "UnsupportedMediaType",
+			userID:         "1",
+			contentType:    "application/xml",
+			mockFunc:       func(m *MockURLService) {},
+			expectedStatus: http.StatusUnsupportedMediaType,
+			expectedBody:   "Unsupported Media Type\n",
+		},
+		{
+			name:           "UnknownFormat",
+			userID:         "1",
+			query:          "?format=xlsx",
+			mockFunc:       func(m *MockURLService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Bad Request\n",
+		},
+		{
+			name:           "Unauthorized",
+			body:           "https://google.com\n",
+			mockFunc:       func(m *MockURLService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc := new(MockURLService)
+			test.mockFunc(svc)
+
+			handler := New(logger, svc, model.ImportLimits{})
+
+			req := httptest.NewRequest(http.MethodPost, "/api/user/urls/import"+test.query, strings.NewReader(test.body))
+			if test.contentType != "" {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+			if test.userID != "" {
+				req = req.WithContext(context.WithValue(req.Context(), model.UserIDKey, test.userID))
+			}
+
+			w := httptest.NewRecorder()
+			handler(w, req)
+
+			resp := w.Result()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+			if test.isJSONResponse {
+				assert.JSONEq(t, test.expectedBody, string(body))
+			} else {
+				assert.Equal(t, test.expectedBody, string(body))
+			}
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+// brokenReader имитирует обрыв соединения посреди загрузки
+type brokenReader struct{}
+
+func (brokenReader) Read(p []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+// created успешный результат импорта n строк, начиная со строки from
+func created(from, n int) []model.ImportResult {
+	results := make([]model.ImportResult, 0, n)
+	for line := from; line < from+n; line++ {
+		results = append(results, model.ImportResult{Line: line, OriginalURL: "https://google.com", Status: model.ImportStatusCreated})
+	}
+	return results
+}
+
+func TestImportURLsHandler_ReadFailure(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	var upload strings.Builder
+	for i := 0; i < chunkSize+1; i++ {
+		upload.WriteString("{\"original_url\":\"https://google.com\"}\n")
+	}
+
+	tests := []struct {
+		name          string
+		body          io.Reader
+		expectedError string
+	}{
+		{
+			name:          "LineTooLong",
+			body:          strings.NewReader(upload.String() + "{\"original_url\":\"" + strings.Repeat("a", maxLineSize) + "\"}\n"),
+			expectedError: "read upload: line 102 is longer than 1048576 bytes",
+		},
+		{
+			name:          "BrokenBody",
+			body:          io.MultiReader(strings.NewReader(upload.String()), brokenReader{}),
+			expectedError: "read upload: connection reset",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc := new(MockURLService)
+			svc.On("ImportURLs", mock.Anything, mock.MatchedBy(func(rows []model.ImportRow) bool { return len(rows) == chunkSize })).
+				Return(created(1, chunkSize)).Once()
+			svc.On("ImportURLs", mock.Anything, []model.ImportRow{{Line: chunkSize + 1, OriginalURL: "https://google.com"}}).
+				Return(created(chunkSize+1, 1)).Once()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/user/urls/import?format=ndjson", test.body)
+			req = req.WithContext(context.WithValue(req.Context(), model.UserIDKey, "1"))
+			w := httptest.NewRecorder()
+			New(logger, svc, model.ImportLimits{})(w, req)
+
+			resp := w.Result()
+			var report model.ImportReport
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+			require.NoError(t, resp.Body.Close())
+
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+			assert.Equal(t, test.expectedError, report.Error)
+			assert.Equal(t, chunkSize+1, report.Total, "rows read before the failure are saved and reported")
+			assert.Equal(t, chunkSize+1, report.Created)
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestImportURLsHandler_Limits(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	const line = "{\"original_url\":\"https://google.com\"}\n"
+	upload := strings.Repeat(line, chunkSize+1)
+
+	tests := []struct {
+		name          string
+		limits        model.ImportLimits
+		contentLength int64
+		expectedError string
+		expectedTotal int
+	}{
+		{
+			name:          "ContentLength",
+			limits:        model.ImportLimits{MaxBytes: int64(len(upload) - 1)},
+			contentLength: int64(len(upload)),
+		},
+		{
+			name:          "StreamTooLarge",
+			limits:        model.ImportLimits{MaxBytes: int64(len(line) * chunkSize)},
+			contentLength: -1,
+			expectedError: fmt.Sprintf("upload is larger than %d bytes", len(line)*chunkSize),
+			expectedTotal: chunkSize,
+		},
+		{
+			name:          "TooManyRows",
+			limits:        model.ImportLimits{MaxBytes: int64(len(upload)), MaxRows: chunkSize},
+			contentLength: int64(len(upload)),
+			expectedError: fmt.Sprintf("upload has more than %d rows", chunkSize),
+			expectedTotal: chunkSize,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc := new(MockURLService)
+			if test.expectedTotal > 0 {
+				svc.On("ImportURLs", mock.Anything, mock.MatchedBy(func(rows []model.ImportRow) bool { return len(rows) == chunkSize })).
+					Return(created(1, chunkSize)).Once()
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/user/urls/import?format=ndjson", strings.NewReader(upload))
+			req.ContentLength = test.contentLength
+			req = req.WithContext(context.WithValue(req.Context(), model.UserIDKey, "1"))
+			w := httptest.NewRecorder()
+			New(logger, svc, test.limits)(w, req)
+
+			resp := w.Result()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+			if test.expectedTotal == 0 {
+				assert.Equal(t, "Request Entity Too Large\n", string(body), "oversized upload is rejected before reading")
+				svc.AssertNotCalled(t, "ImportURLs", mock.Anything, mock.Anything)
+				return
+			}
+			var report model.ImportReport
+			require.NoError(t, json.Unmarshal(body, &report))
+			assert.Equal(t, test.expectedError, report.Error)
+			assert.Equal(t, test.expectedTotal, report.Total, "rows within the limit are saved and reported")
+			svc.AssertExpectations(t)
+		})
+	}
+}
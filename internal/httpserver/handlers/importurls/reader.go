@@ -0,0 +1,145 @@
+package importurls
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// maxLineSize наибольшая длина строки NDJSON
+const maxLineSize = 1 << 20
+
+// defaultColumns порядок колонок CSV без заголовка
+var defaultColumns = []string{"original_url", "alias", "tags", "expires_at"}
+
+// rowReader читает загрузку по одной строке. Конец загрузки io.EOF,
+// ошибка в отдельной строке *rowError, остальные ошибки прерывают импорт
+type rowReader interface {
+	next() (model.ImportRow, error)
+}
+
+// rowError ошибка разбора одной строки загрузки
+type rowError struct {
+	line   int
+	reason string
+}
+
+func (e *rowError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.line, e.reason)
+}
+
+// csvReader разбор CSV. Первая строка считается заголовком, если в ней есть колонка original_url
+type csvReader struct {
+	r       *csv.Reader
+	columns map[string]int
+}
+
+func newCSVReader(r io.Reader) *csvReader {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+	return &csvReader{r: reader}
+}
+
+func (c *csvReader) next() (model.ImportRow, error) {
+	for {
+		record, err := c.r.Read()
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return model.ImportRow{}, &rowError{line: parseErr.StartLine, reason: parseErr.Err.Error()}
+		}
+		if err != nil {
+			return model.ImportRow{}, err
+		}
+		line, _ := c.r.FieldPos(0)
+
+		if c.columns == nil {
+			record[0] = strings.TrimPrefix(record[0], "\ufeff")
+			if c.header(record) {
+				continue
+			}
+		}
+		field := func(name string) string {
+			if i, ok := c.columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		return model.ImportRow{
+			Line:        line,
+			OriginalURL: field("original_url"),
+			Alias:       field("alias"),
+			Tags:        splitTags(field("tags")),
+			ExpiresAt:   field("expires_at"),
+		}, nil
+	}
+}
+
+// header запоминает порядок колонок и сообщает, была ли record заголовком
+func (c *csvReader) header(record []string) bool {
+	c.columns = make(map[string]int, len(defaultColumns))
+	for i, name := range record {
+		c.columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := c.columns["original_url"]; ok {
+		return true
+	}
+	clear(c.columns)
+	for i, name := range defaultColumns {
+		c.columns[name] = i
+	}
+	return false
+}
+
+// splitTags делит колонку tags по пробелам, запятым и точкам с запятой
+func splitTags(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	return strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\t'
+	})
+}
+
+// ndjsonReader разбор NDJSON, по одному json объекту в строке
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newNDJSONReader(r io.Reader) *ndjsonReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	return &ndjsonReader{scanner: scanner}
+}
+
+func (n *ndjsonReader) next() (model.ImportRow, error) {
+	for n.scanner.Scan() {
+		n.line++
+		data := n.scanner.Bytes()
+		if len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+		var row model.ImportRow
+		if err := json.Unmarshal(data, &row); err != nil {
+			return model.ImportRow{}, &rowError{line: n.line, reason: "invalid json: " + err.Error()}
+		}
+		row.Line = n.line
+		return row, nil
+	}
+	if err := n.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return model.ImportRow{}, fmt.Errorf("line %d is longer than %d bytes", n.line+1, maxLineSize)
+		}
+		return model.ImportRow{}, err
+	}
+	return model.ImportRow{}, io.EOF
+}
@@ -0,0 +1,86 @@
+// Package qr предоставляет QR код короткой ссылки в PNG или SVG.
+package qr
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/httpserver/handlers/apierror"
+	"github.com/ArtShib/urlshortener/internal/lib/qrcode"
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// cacheControl QR код хранится только у клиента и перепроверяется по ETag при каждом запросе,
+// чтобы удаленная или истекшая ссылка сразу отдавала 410
+const cacheControl = "private, no-cache"
+
+// URLService интерфейс сервиса для получения короткой ссылки.
+type URLService interface {
+	GetID(ctx context.Context, shortCode string) (*model.URL, error)
+}
+
+// New конструктор HandlerFunc для QR кода короткой ссылки.
+// Параметры: format (png|svg), size в пикселях, level коррекции (L|M|Q|H), margin в модулях.
+func New(log *slog.Logger, svc URLService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "QR.Get"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		log.Info("received request")
+
+		opts, err := qrcode.ParseOptions(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		url, err := svc.GetID(r.Context(), chi.URLParam(r, "shortCode"))
+		if status, message, ok := apierror.Status(err); ok {
+			http.Error(w, message, status)
+			return
+		}
+		if err != nil {
+			log.Error("service GetID", "error", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		if url.DeletedFlag || url.IsExpired(time.Now()) {
+			http.Error(w, http.StatusText(http.StatusGone), http.StatusGone)
+			return
+		}
+
+		sum := sha256.Sum256(fmt.Appendf(nil, "%s|%+v", url.ShortURL, opts))
+		etag := fmt.Sprintf(`"%x"`, sum[:16])
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", cacheControl)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		var buf bytes.Buffer
+		if err := qrcode.Render(&buf, url.ShortURL, opts); err != nil {
+			log.Error("render qr", "error", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", opts.ContentType())
+		w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+		w.WriteHeader(http.StatusOK)
+		if _, err := buf.WriteTo(w); err != nil {
+			log.Error("write response", "error", err)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package qr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/png"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockURLService struct {
+	mock.Mock
+}
+
+func (m *MockURLService) GetID(ctx context.Context, shortCode string) (*model.URL, error) {
+	args := m.Called(ctx, shortCode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.URL), args.Error(1)
+}
+
+func TestQRHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	link := &model.URL{UUID: "abc", ShortURL: "http://localhost/abc", OriginalURL: "https://google.com"}
+	expired := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name                string
+		query               string
+		mockFunc            func(m *MockURLService)
+		expectedStatus      int
+		expectedContentType string
+	}{
+		{
+			name: "PNG",
+			mockFunc: func(m *MockURLService) {
+				m.On("GetID", mock.Anything, "abc").Return(link, nil).Once()
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "image/png",
+		},
+		{
+			name:  "SVG",
+			query: "?format=svg&level=H&margin=2",
+			mockFunc: func(m *MockURLService) {
+				m.On("GetID", mock.Anything, "abc").Return(link, nil).Once()
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "image/svg+xml",
+		},
+		{
+			name:                "InvalidOptions",
+			query:               "?size=5",
+			mockFunc:            func(m *MockURLService) {},
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: "text/plain; charset=utf-8",
+		},
+		{
+			name: "NotFound",
+			mockFunc: func(m *MockURLService) {
+				m.On("GetID", mock.Anything, "abc").Return(nil, model.ErrURLNotFound).Once()
+			},
+			expectedStatus:      http.StatusNotFound,
+			expectedContentType: "text/plain; charset=utf-8",
+		},
+		{
+			name: "Deleted",
+			mockFunc: func(m *MockURLService) {
+				m.On("GetID", mock.Anything, "abc").Return(&model.URL{UUID: "abc", ShortURL: "http://localhost/abc", DeletedFlag: true}, nil).Once()
+			},
+			expectedStatus:      http.StatusGone,
+			expectedContentType: "text/plain; charset=utf-8",
+		},
+		{
+			name: "Expired",
+			mockFunc: func(m *MockURLService) {
+				m.On("GetID", mock.Anything, "abc").Return(&model.URL{UUID: "abc", ShortURL: "http://localhost/abc", ExpiresAt: &expired}, nil).Once()
+			},
+			expectedStatus:      http.StatusGone,
+			expectedContentType: "text/plain; charset=utf-8",
+		},
+		{
+			name: "InternalError",
+			mockFunc: func(m *MockURLService) {
+				m.On("GetID", mock.Anything, "abc").Return(nil, errors.New("database error")).Once()
+			},
+			expectedStatus:      http.StatusInternalServerError,
+			expectedContentType: "text/plain; charset=utf-8",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc := new(MockURLService)
+			test.mockFunc(svc)
+
+			resp, body := serve(t, New(logger, svc), test.query, "")
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+			assert.Equal(t, test.expectedContentType, resp.Header.Get("Content-Type"))
+			switch test.expectedContentType {
+			case "image/png":
+				_, err := png.Decode(bytes.NewReader(body))
+				require.NoError(t, err)
+			case "image/svg+xml":
+				assert.True(t, bytes.HasPrefix(body, []byte("<svg ")))
+			}
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestQRHandler_ETag(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := new(MockURLService)
+	svc.On("GetID", mock.Anything, "abc").Return(&model.URL{UUID: "abc", ShortURL: "http://localhost/abc"}, nil)
+	handler := New(logger, svc)
+
+	resp, _ := serve(t, handler, "", "")
+	etag := resp.Header.Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Equal(t, "private, no-cache", resp.Header.Get("Cache-Control"))
+
+	resp, body := serve(t, handler, "", etag)
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	assert.Empty(t, body)
+
+	resp, _ = serve(t, handler, "?size=512", etag)
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "other options give another image")
+	assert.NotEqual(t, etag, resp.Header.Get("ETag"))
+}
+
+func TestQRHandler_RevalidateDeleted(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := new(MockURLService)
+	svc.On("GetID", mock.Anything, "abc").Return(&model.URL{UUID: "abc", ShortURL: "http://localhost/abc"}, nil).Once()
+	svc.On("GetID", mock.Anything, "abc").Return(&model.URL{UUID: "abc", ShortURL: "http://localhost/abc", DeletedFlag: true}, nil).Once()
+	handler := New(logger, svc)
+
+	resp, _ := serve(t, handler, "", "")
+	etag := resp.Header.Get("ETag")
+	require.NotEmpty(t, etag)
+
+	resp, _ = serve(t, handler, "", etag)
+	assert.Equal(t, http.StatusGone, resp.StatusCode, "cached code is not served for a deleted link")
+	svc.AssertExpectations(t)
+}
+
+func serve(t *testing.T, handler http.HandlerFunc, query, ifNoneMatch string) (*http.Response, []byte) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/abc/qr"+query, nil)
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("shortCode", "abc")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	resp := w.Result()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	return resp, body
+}
@@ -0,0 +1,53 @@
+// Package restoreurls предоставляет функциональность для асинхронного восстановления удалённых URL-адресов.
+package restoreurls
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// WorkerPoolRestore интерфейс воркера, который обрабатывает удаление и восстановление одним потоком пачек.
+type WorkerPoolRestore interface {
+	AddRequest(req model.DeleteRequest)
+}
+
+// New конструктор HandlerFunc для обработки запросов на восстановление URL.
+func New(log *slog.Logger, svc WorkerPoolRestore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "Restore.Post"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		log.Info("received request")
+
+		userID, ok := r.Context().Value(model.UserIDKey).(string)
+		if !ok || userID == "" {
+			log.Error("Unauthorized", "error", http.StatusText(http.StatusUnauthorized))
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		var uuids []string
+		decoder := json.NewDecoder(r.Body)
+		if err := decoder.Decode(&uuids); err != nil {
+			log.Error("JsonDecode", "error", err)
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+
+		svc.AddRequest(model.DeleteRequest{
+			UserID:  userID,
+			UUIDs:   uuids,
+			Restore: true,
+		})
+
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
@@ -0,0 +1,95 @@
+package restoreurls
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockURLService struct {
+	mock.Mock
+}
+
+func (m *MockURLService) AddRequest(req model.DeleteRequest) {
+	m.Called(req)
+}
+
+func TestRestoreURLsHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name           string
+		inputBody      string
+		userID         string
+		mockFunc       func(m *MockURLService)
+		expectedStatus int
+	}{
+		{
+			name:      "Success",
+			inputBody: `["aedsadd","restfgt"]`,
+			userID:    "2",
+			mockFunc: func(m *MockURLService) {
+				m.On("AddRequest", mock.MatchedBy(func(req model.DeleteRequest) bool {
+					return req.UserID == "2" && req.Restore && len(req.UUIDs) == 2 && req.UUIDs[0] == "aedsadd"
+				})).
+					Once()
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "Unauthorized",
+			inputBody:      `["aedsadd","restfgt"]`,
+			userID:         "",
+			mockFunc:       func(m *MockURLService) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "BadRequest",
+			inputBody:      `["aedsadd]`,
+			userID:         "2",
+			mockFunc:       func(m *MockURLService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc := new(MockURLService)
+
+			test.mockFunc(svc)
+
+			handler := New(logger, svc)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/user/urls/restore", bytes.NewBufferString(test.inputBody))
+
+			if test.userID != "" {
+				ctx := context.WithValue(req.Context(), model.UserIDKey, test.userID)
+				req = req.WithContext(ctx)
+			}
+
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			resp := w.Result()
+			defer func() {
+				if err := resp.Body.Close(); err != nil {
+					require.NoError(t, err)
+				}
+			}()
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+
+			svc.AssertExpectations(t)
+		})
+	}
+}
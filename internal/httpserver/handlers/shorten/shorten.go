@@ -6,15 +6,18 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
+	"github.com/ArtShib/urlshortener/internal/httpserver/handlers/apierror"
 	"github.com/ArtShib/urlshortener/internal/model"
 	"github.com/go-chi/chi/v5/middleware"
 )
 
 // URLService интерфейс сервиса для создания сокращенного url.
 type URLService interface {
-	Shorten(ctx context.Context, url string) (string, error)
+	Shorten(ctx context.Context, req model.RequestShortener) (string, error)
 }
 
 // New конструктор HandlerFunc для создания сокращенного url.
@@ -43,8 +46,28 @@ func New(log *slog.Logger, svc URLService) http.HandlerFunc {
 			return
 		}
 
-		shortURL, err := svc.Shorten(r.Context(), string(body))
+		opts, err := parseOptions(r.URL.Query())
+		if err != nil {
+			log.Error("parse options", "error", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// пароль не передаётся в query string, чтобы не попасть в логи
+		opts.Password = r.Header.Get(model.PasswordHeader)
+		shortURL, err := svc.Shorten(r.Context(), model.RequestShortener{
+			URL:            string(body),
+			ShortenOptions: opts,
+		})
 
+		if status, message, ok := apierror.Status(err); ok {
+			if errors.Is(err, model.ErrURLBlocked) {
+				audit := model.AuditFromContext(r.Context())
+				audit.Action = model.ActionBlocked
+				audit.OriginalURL = string(body)
+			}
+			http.Error(w, message, status)
+			return
+		}
 		if err != nil && !errors.Is(err, model.ErrURLConflict) {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -59,10 +82,49 @@ func New(log *slog.Logger, svc URLService) http.HandlerFunc {
 			w.WriteHeader(http.StatusCreated)
 		}
 
-		w.Header().Set("OriginalURL", string(body))
+		model.AuditFromContext(r.Context()).OriginalURL = string(body)
 		_, err = w.Write([]byte(shortURL))
 		if err != nil {
 			log.Error("response write failed", "error", err)
 		}
 	}
 }
+
+// parseOptions читает параметры сокращения из query string
+func parseOptions(query url.Values) (model.ShortenOptions, error) {
+	opts := model.ShortenOptions{
+		Alias: query.Get("alias"),
+		TTL:   query.Get("ttl"),
+		Tags:  query["tag"],
+		Title: query.Get("title"),
+		Note:  query.Get("note"),
+	}
+	opts.ForceNew, _ = strconv.ParseBool(query.Get("force_new"))
+	opts.Preview, _ = strconv.ParseBool(query.Get("preview"))
+	opts.Passthrough, _ = strconv.ParseBool(query.Get("passthrough"))
+	utm := model.UTM{
+		Source:   query.Get("utm_source"),
+		Medium:   query.Get("utm_medium"),
+		Campaign: query.Get("utm_campaign"),
+		Term:     query.Get("utm_term"),
+		Content:  query.Get("utm_content"),
+	}
+	if !utm.IsZero() {
+		opts.UTM = &utm
+	}
+	if raw := query.Get("max_clicks"); raw != "" {
+		maxClicks, err := strconv.Atoi(raw)
+		if err != nil {
+			return opts, model.NewRequestError(model.ErrInvalidMaxClicks, "max_clicks must be an integer")
+		}
+		opts.MaxClicks = maxClicks
+	}
+	if raw := query.Get("expires_at"); raw != "" {
+		expiresAt, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return opts, model.NewRequestError(model.ErrInvalidExpiry, "expires_at must be in RFC 3339 format")
+		}
+		opts.ExpiresAt = &expiresAt
+	}
+	return opts, nil
+}
@@ -20,8 +20,8 @@ type MockURLService struct {
 	mock.Mock
 }
 
-func (m *MockURLService) Shorten(ctx context.Context, url string) (string, error) {
-	args := m.Called(ctx, url)
+func (m *MockURLService) Shorten(ctx context.Context, req model.RequestShortener) (string, error) {
+	args := m.Called(ctx, req)
 	return args.String(0), args.Error(1)
 }
 
@@ -31,6 +31,7 @@ func TestShortenHandler(t *testing.T) {
 	tests := []struct {
 		name           string
 		inputBody      string
+		query          string
 		mockFunc       func(m *MockURLService, body string)
 		expectedStatus int
 		expectedBody   string
@@ -39,29 +40,114 @@ func TestShortenHandler(t *testing.T) {
 			name:      "Success",
 			inputBody: "https://google.com",
 			mockFunc: func(m *MockURLService, body string) {
-				m.On("Shorten", mock.Anything, body).
+				m.On("Shorten", mock.Anything, model.RequestShortener{URL: body}).
 					Return("http://localhost/sdfdfg", nil).
 					Once()
 			},
 			expectedStatus: http.StatusCreated,
 			expectedBody:   "http://localhost/sdfdfg",
 		},
+		{
+			name:      "ForceNew",
+			inputBody: "https://google.com",
+			query:     "?force_new=true",
+			mockFunc: func(m *MockURLService, body string) {
+				m.On("Shorten", mock.Anything, model.RequestShortener{URL: body, ShortenOptions: model.ShortenOptions{ForceNew: true}}).
+					Return("http://localhost/qwerty", nil).
+					Once()
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   "http://localhost/qwerty",
+		},
+		{
+			name:      "Metadata",
+			inputBody: "https://google.com",
+			query:     "?tag=work&tag=docs&title=Search",
+			mockFunc: func(m *MockURLService, body string) {
+				m.On("Shorten", mock.Anything, model.RequestShortener{URL: body, ShortenOptions: model.ShortenOptions{
+					Tags: []string{"work", "docs"}, Title: "Search"}}).
+					Return("http://localhost/qwerty", nil).
+					Once()
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   "http://localhost/qwerty",
+		},
+		{
+			name:      "Redirect",
+			inputBody: "https://google.com",
+			query:     "?passthrough=1&utm_source=news&utm_campaign=spring",
+			mockFunc: func(m *MockURLService, body string) {
+				m.On("Shorten", mock.Anything, model.RequestShortener{URL: body, ShortenOptions: model.ShortenOptions{
+					Passthrough: true, UTM: &model.UTM{Source: "news", Campaign: "spring"}}}).
+					Return("http://localhost/qwerty", nil).
+					Once()
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   "http://localhost/qwerty",
+		},
 		{
 			name:      "Conflict",
 			inputBody: "https://google.com",
 			mockFunc: func(m *MockURLService, body string) {
-				m.On("Shorten", mock.Anything, body).
+				m.On("Shorten", mock.Anything, model.RequestShortener{URL: body}).
 					Return("http://localhost/sdfdfg", model.ErrURLConflict).
 					Once()
 			},
 			expectedStatus: http.StatusConflict,
 			expectedBody:   "http://localhost/sdfdfg",
 		},
+		{
+			name:      "Alias",
+			inputBody: "https://google.com",
+			query:     "?alias=spring-sale",
+			mockFunc: func(m *MockURLService, body string) {
+				m.On("Shorten", mock.Anything, model.RequestShortener{URL: body, ShortenOptions: model.ShortenOptions{Alias: "spring-sale"}}).
+					Return("http://localhost/spring-sale", nil).
+					Once()
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   "http://localhost/spring-sale",
+		},
+		{
+			name:      "AliasTaken",
+			inputBody: "https://google.com",
+			query:     "?alias=spring-sale",
+			mockFunc: func(m *MockURLService, body string) {
+				m.On("Shorten", mock.Anything, model.RequestShortener{URL: body, ShortenOptions: model.ShortenOptions{Alias: "spring-sale"}}).
+					Return("", model.ErrShortCodeConflict).
+					Once()
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "alias is already taken\n",
+		},
+		{
+			name:      "InvalidAlias",
+			inputBody: "https://google.com",
+			query:     "?alias=api",
+			mockFunc: func(m *MockURLService, body string) {
+				m.On("Shorten", mock.Anything, model.RequestShortener{URL: body, ShortenOptions: model.ShortenOptions{Alias: "api"}}).
+					Return("", model.NewRequestError(model.ErrInvalidAlias, `"api" is reserved`)).
+					Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid alias: \"api\" is reserved\n",
+		},
+		{
+			name:      "Blocked",
+			inputBody: "https://evil.com",
+			mockFunc: func(m *MockURLService, body string) {
+				m.On("Shorten", mock.Anything, model.RequestShortener{URL: body}).
+					Return("", model.NewRequestError(model.ErrURLBlocked, "domain evil.com is blocked")).
+					Once()
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   "url is blocked: domain evil.com is blocked\n",
+		},
 		{
 			name:      "InternalError",
 			inputBody: "https://google.com",
 			mockFunc: func(m *MockURLService, body string) {
-				m.On("Shorten", mock.Anything, body).
+				m.On("Shorten", mock.Anything, model.RequestShortener{URL: body}).
 					Return("", errors.New(http.StatusText(http.StatusInternalServerError))).
 					Once()
 			},
@@ -77,7 +163,7 @@ func TestShortenHandler(t *testing.T) {
 
 			handler := New(logger, svc)
 
-			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(test.inputBody))
+			req := httptest.NewRequest(http.MethodPost, "/"+test.query, bytes.NewBufferString(test.inputBody))
 			w := httptest.NewRecorder()
 
 			handler(w, req)
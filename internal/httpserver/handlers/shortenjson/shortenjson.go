@@ -1,22 +1,28 @@
 package shortenjson
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
 
+	"github.com/ArtShib/urlshortener/internal/httpserver/handlers/apierror"
+	"github.com/ArtShib/urlshortener/internal/lib/qrcode"
 	"github.com/ArtShib/urlshortener/internal/model"
 	"github.com/go-chi/chi/v5/middleware"
 )
 
 // URLService интерфейс сервиса для создания сокращенного url. ответ json
 type URLService interface {
-	ShortenJSON(ctx context.Context, url string) (*model.ResponseShortener, error)
+	ShortenJSON(ctx context.Context, req model.RequestShortener) (*model.ResponseShortener, error)
 }
 
 // New конструктор HandlerFunc для создания сокращенного url. ответ json
+// С параметром qr=true в ответ добавляется QR код ссылки, его вид задают параметры format, size, level и margin.
 func New(log *slog.Logger, svc URLService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "ShortenJSON.Post"
@@ -28,6 +34,16 @@ func New(log *slog.Logger, svc URLService) http.HandlerFunc {
 
 		log.Info("received request")
 
+		var qrOpts *qrcode.Options
+		if withQR, _ := strconv.ParseBool(r.URL.Query().Get("qr")); withQR {
+			opts, err := qrcode.ParseOptions(r.URL.Query())
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			qrOpts = &opts
+		}
+
 		var req model.RequestShortener
 
 		decoder := json.NewDecoder(r.Body)
@@ -43,20 +59,40 @@ func New(log *slog.Logger, svc URLService) http.HandlerFunc {
 			return
 		}
 
-		responseShortener, err := svc.ShortenJSON(r.Context(), req.URL)
+		responseShortener, err := svc.ShortenJSON(r.Context(), req)
+		if status, message, ok := apierror.Status(err); ok {
+			log.Error("service shortenJSON", "error", err)
+			if errors.Is(err, model.ErrURLBlocked) {
+				audit := model.AuditFromContext(r.Context())
+				audit.Action = model.ActionBlocked
+				audit.OriginalURL = req.URL
+			}
+			http.Error(w, message, status)
+			return
+		}
 		if err != nil && !errors.Is(err, model.ErrURLConflict) {
 			log.Error("service shortenJSON", "error", err)
 			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 			return
 		}
 
+		if qrOpts != nil {
+			var buf bytes.Buffer
+			if err := qrcode.Render(&buf, responseShortener.Result, *qrOpts); err != nil {
+				log.Error("render qr", "error", err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			responseShortener.QR = "data:" + qrOpts.ContentType() + ";base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 		if errors.Is(err, model.ErrURLConflict) {
 			w.WriteHeader(http.StatusConflict)
 		} else {
 			w.WriteHeader(http.StatusCreated)
 		}
-		w.Header().Set("OriginalURL", req.URL)
+		model.AuditFromContext(r.Context()).OriginalURL = req.URL
 		encoder := json.NewEncoder(w)
 		if err := encoder.Encode(responseShortener); err != nil {
 			log.Error("Encode response", "error", err)
@@ -3,6 +3,7 @@ package shortenjson
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"io"
@@ -11,6 +12,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/ArtShib/urlshortener/internal/lib/qrcode"
 	"github.com/ArtShib/urlshortener/internal/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -21,8 +23,8 @@ type MockURLService struct {
 	mock.Mock
 }
 
-func (m *MockURLService) ShortenJSON(ctx context.Context, url string) (*model.ResponseShortener, error) {
-	args := m.Called(ctx, url)
+func (m *MockURLService) ShortenJSON(ctx context.Context, req model.RequestShortener) (*model.ResponseShortener, error) {
+	args := m.Called(ctx, req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -32,10 +34,15 @@ func (m *MockURLService) ShortenJSON(ctx context.Context, url string) (*model.Re
 func TestShortenJSONHandler(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 
+	var svg bytes.Buffer
+	require.NoError(t, qrcode.Render(&svg, "http://localhost/sdfdfg", qrcode.Options{Format: qrcode.FormatSVG, Size: 128, Level: qrcode.LevelM, Margin: 4}))
+	qrSVG := "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString(svg.Bytes())
+
 	tests := []struct {
 		name           string
 		inputBody      string
-		mockFunc       func(m *MockURLService, body string)
+		query          string
+		mockFunc       func(m *MockURLService, req model.RequestShortener)
 		expectedStatus int
 		expectedBody   string
 		isJSONResponse bool
@@ -43,8 +50,8 @@ func TestShortenJSONHandler(t *testing.T) {
 		{
 			name:      "Success",
 			inputBody: `{"url": "https://google.com"}`,
-			mockFunc: func(m *MockURLService, body string) {
-				m.On("ShortenJSON", mock.Anything, body).
+			mockFunc: func(m *MockURLService, req model.RequestShortener) {
+				m.On("ShortenJSON", mock.Anything, req).
 					Return(&model.ResponseShortener{Result: "http://localhost/sdfdfg"}, nil).
 					Once()
 			},
@@ -52,11 +59,45 @@ func TestShortenJSONHandler(t *testing.T) {
 			expectedBody:   `{"result": "http://localhost/sdfdfg"}`,
 			isJSONResponse: true,
 		},
+		{
+			name:      "ForceNew",
+			inputBody: `{"url": "https://google.com", "force_new": true}`,
+			mockFunc: func(m *MockURLService, req model.RequestShortener) {
+				m.On("ShortenJSON", mock.Anything, model.RequestShortener{URL: "https://google.com", ShortenOptions: model.ShortenOptions{ForceNew: true}}).
+					Return(&model.ResponseShortener{Result: "http://localhost/qwerty"}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"result": "http://localhost/qwerty"}`,
+			isJSONResponse: true,
+		},
+		{
+			name:      "QR",
+			inputBody: `{"url": "https://google.com"}`,
+			query:     "?qr=true&format=svg&size=128",
+			mockFunc: func(m *MockURLService, req model.RequestShortener) {
+				m.On("ShortenJSON", mock.Anything, req).
+					Return(&model.ResponseShortener{Result: "http://localhost/sdfdfg"}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"result": "http://localhost/sdfdfg", "qr": "` + qrSVG + `"}`,
+			isJSONResponse: true,
+		},
+		{
+			name:           "InvalidQROptions",
+			inputBody:      `{"url": "https://google.com"}`,
+			query:          "?qr=true&level=X",
+			mockFunc:       func(m *MockURLService, req model.RequestShortener) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid qr options: level must be L, M, Q or H\n",
+			isJSONResponse: false,
+		},
 		{
 			name:      "Conflict",
 			inputBody: `{"url": "https://google.com"}`,
-			mockFunc: func(m *MockURLService, body string) {
-				m.On("ShortenJSON", mock.Anything, body).
+			mockFunc: func(m *MockURLService, req model.RequestShortener) {
+				m.On("ShortenJSON", mock.Anything, req).
 					Return(&model.ResponseShortener{Result: "http://localhost/sdfdfg"}, model.ErrURLConflict).
 					Once()
 			},
@@ -64,11 +105,35 @@ func TestShortenJSONHandler(t *testing.T) {
 			expectedBody:   `{"result": "http://localhost/sdfdfg"}`,
 			isJSONResponse: true,
 		},
+		{
+			name:      "AliasTaken",
+			inputBody: `{"url": "https://google.com", "alias": "spring-sale"}`,
+			mockFunc: func(m *MockURLService, req model.RequestShortener) {
+				m.On("ShortenJSON", mock.Anything, req).
+					Return(nil, model.ErrShortCodeConflict).
+					Once()
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "alias is already taken\n",
+			isJSONResponse: false,
+		},
+		{
+			name:      "InvalidURL",
+			inputBody: `{"url": "javascript:alert(1)"}`,
+			mockFunc: func(m *MockURLService, req model.RequestShortener) {
+				m.On("ShortenJSON", mock.Anything, req).
+					Return(nil, model.NewRequestError(model.ErrInvalidURL, "scheme must be one of http, https")).
+					Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid url: scheme must be one of http, https\n",
+			isJSONResponse: false,
+		},
 		{
 			name:      "InternalError",
 			inputBody: `{"url": "https://google.com"}`,
-			mockFunc: func(m *MockURLService, body string) {
-				m.On("ShortenJSON", mock.Anything, body).
+			mockFunc: func(m *MockURLService, req model.RequestShortener) {
+				m.On("ShortenJSON", mock.Anything, req).
 					Return(&model.ResponseShortener{}, errors.New(http.StatusText(http.StatusInternalServerError))).
 					Once()
 			},
@@ -86,11 +151,11 @@ func TestShortenJSONHandler(t *testing.T) {
 			if err := json.Unmarshal([]byte(test.inputBody), &reqBody); err != nil {
 				require.NoError(t, err)
 			}
-			test.mockFunc(svc, reqBody.URL)
+			test.mockFunc(svc, reqBody)
 
 			handler := New(logger, svc)
 
-			req := httptest.NewRequest(http.MethodPost, "/api/shorten", bytes.NewBufferString(test.inputBody))
+			req := httptest.NewRequest(http.MethodPost, "/api/shorten"+test.query, bytes.NewBufferString(test.inputBody))
 			w := httptest.NewRecorder()
 
 			handler(w, req)
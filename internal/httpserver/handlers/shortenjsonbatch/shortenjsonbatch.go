@@ -3,9 +3,11 @@ package shortenjsonbatch
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 
+	"github.com/ArtShib/urlshortener/internal/httpserver/handlers/apierror"
 	"github.com/ArtShib/urlshortener/internal/model"
 	"github.com/go-chi/chi/v5/middleware"
 )
@@ -43,6 +45,19 @@ func New(log *slog.Logger, svc URLService) http.HandlerFunc {
 		}
 
 		responseShortener, err := svc.ShortenJSONBatch(r.Context(), req)
+		if status, message, ok := apierror.Status(err); ok {
+			log.Error("service ShortenJSONBatch", "error", err)
+			if errors.Is(err, model.ErrURLBlocked) {
+				audit := model.AuditFromContext(r.Context())
+				audit.Action = model.ActionBlocked
+				var itemErr *model.BatchItemError
+				if errors.As(err, &itemErr) {
+					audit.OriginalURL = itemErr.OriginalURL
+				}
+			}
+			http.Error(w, message, status)
+			return
+		}
 		if err != nil {
 			log.Error("service ShortenJSONBatch", "error", err)
 			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
@@ -39,6 +40,8 @@ func TestShortenJSONHandler(t *testing.T) {
 		expectedStatus int
 		expectedBody   string
 		isJSONResponse bool
+		expectedAction string
+		expectedURL    string
 	}{
 		{
 			name:      "Success",
@@ -54,6 +57,36 @@ func TestShortenJSONHandler(t *testing.T) {
 			expectedBody:   `[{"correlation_id":"uuid1","short_url":"http://localhost/sdfdfg"},{"correlation_id":"uuid2","short_url":"http://localhost/asdasd"}]`,
 			isJSONResponse: true,
 		},
+		{
+			name:      "InvalidURL",
+			inputBody: `[{"correlation_id":"uuid1","original_url":"https:///path"}]`,
+			mockFunc: func(m *MockURLService, urls model.RequestShortenerBatchArray) {
+				m.On("ShortenJSONBatch", mock.Anything, urls).
+					Return(nil, model.NewRequestError(model.ErrInvalidURL, `correlation_id "uuid1": url must have a host`)).
+					Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid url: correlation_id \"uuid1\": url must have a host\n",
+			isJSONResponse: false,
+		},
+		{
+			name:      "Blocked",
+			inputBody: `[{"correlation_id":"uuid1","original_url":"https://google.com"},{"correlation_id":"uuid2","original_url":"https://evil.com"}]`,
+			mockFunc: func(m *MockURLService, urls model.RequestShortenerBatchArray) {
+				m.On("ShortenJSONBatch", mock.Anything, urls).
+					Return(nil, fmt.Errorf("URLService.ShortenJSONBatch: %w", &model.BatchItemError{
+						CorrelationID: "uuid2",
+						OriginalURL:   "https://evil.com",
+						Err:           model.NewRequestError(model.ErrURLBlocked, `correlation_id "uuid2": domain evil.com is blocked`),
+					})).
+					Once()
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   "url is blocked: correlation_id \"uuid2\": domain evil.com is blocked\n",
+			isJSONResponse: false,
+			expectedAction: model.ActionBlocked,
+			expectedURL:    "https://evil.com",
+		},
 		{
 			name:      "InternalError",
 			inputBody: `[{"correlation_id":"uuid1","original_url":"https://google.com"},{"correlation_id":"uuid2","original_url":"https://yandex.ru"}]`,
@@ -81,6 +114,8 @@ func TestShortenJSONHandler(t *testing.T) {
 			handler := New(logger, svc)
 
 			req := httptest.NewRequest(http.MethodPost, "/api/shorten/batch", bytes.NewBufferString(test.inputBody))
+			ctx, audit := model.NewAuditContext(req.Context())
+			req = req.WithContext(ctx)
 			w := httptest.NewRecorder()
 
 			handler(w, req)
@@ -93,6 +128,8 @@ func TestShortenJSONHandler(t *testing.T) {
 			}()
 
 			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+			assert.Equal(t, test.expectedAction, audit.Action)
+			assert.Equal(t, test.expectedURL, audit.OriginalURL)
 
 			resBody, err := io.ReadAll(resp.Body)
 			defer func() {
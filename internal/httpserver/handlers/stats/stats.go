@@ -0,0 +1,55 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// URLService интерфейс сервиса для получения состояния хранилища.
+type URLService interface {
+	Stats(ctx context.Context) (*model.RepositoryStats, error)
+}
+
+// Purger интерфейс очистки удалённых ссылок, отдающей результаты своих запусков.
+type Purger interface {
+	Stats() model.PurgeStats
+}
+
+// New конструктор HandlerFunc для получения состояния хранилища.
+// purge может быть nil, тогда результаты очистки в ответ не попадают.
+func New(log *slog.Logger, svc URLService, purge Purger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "Stats.Get"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		log.Info("received request")
+
+		stats, err := svc.Stats(r.Context())
+		if err != nil {
+			log.Error("service Stats", "error", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		if purge != nil {
+			purgeStats := purge.Stats()
+			stats.Purge = &purgeStats
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(stats); err != nil {
+			log.Error("Encode response", "error", err)
+			return
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockURLService struct {
+	mock.Mock
+}
+
+func (m *MockURLService) Stats(ctx context.Context) (*model.RepositoryStats, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.RepositoryStats), args.Error(1)
+}
+
+type stubPurger struct {
+	stats model.PurgeStats
+}
+
+func (p *stubPurger) Stats() model.PurgeStats {
+	return p.stats
+}
+
+func TestStatsHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	lastSnapshot := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		purge          Purger
+		mockFunc       func(m *MockURLService)
+		expectedStatus int
+		expectedBody   string
+		isJSONResponse bool
+	}{
+		{
+			name: "Success",
+			mockFunc: func(m *MockURLService) {
+				m.On("Stats", mock.Anything).
+					Return(&model.RepositoryStats{URLs: 2, JournalSize: 128, LastSnapshot: &lastSnapshot}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"urls":2,"journal_size":128,"last_snapshot":"2025-01-02T03:04:05Z"}`,
+			isJSONResponse: true,
+		},
+		{
+			name:  "WithPurge",
+			purge: &stubPurger{stats: model.PurgeStats{LastRun: &lastSnapshot, LastPurged: 3, TotalPurged: 10}},
+			mockFunc: func(m *MockURLService) {
+				m.On("Stats", mock.Anything).
+					Return(&model.RepositoryStats{URLs: 2}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"urls":2,"purge":{"last_run":"2025-01-02T03:04:05Z","last_purged":3,"total_purged":10}}`,
+			isJSONResponse: true,
+		},
+		{
+			name: "InternalError",
+			mockFunc: func(m *MockURLService) {
+				m.On("Stats", mock.Anything).
+					Return(nil, errors.New(http.StatusText(http.StatusInternalServerError))).
+					Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Internal Server Error\n",
+			isJSONResponse: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc := new(MockURLService)
+
+			test.mockFunc(svc)
+			handler := New(logger, svc, test.purge)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/stats", nil)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			resp := w.Result()
+			defer func() {
+				if err := resp.Body.Close(); err != nil {
+					require.NoError(t, err)
+				}
+			}()
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+
+			resBody, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			if test.isJSONResponse {
+				assert.JSONEq(t, test.expectedBody, string(resBody))
+			} else {
+				assert.Equal(t, test.expectedBody, string(resBody))
+			}
+			svc.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// Package urlhistory предоставляет историю изменения адреса короткой ссылки.
+package urlhistory
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/ArtShib/urlshortener/internal/httpserver/handlers/apierror"
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// URLService интерфейс сервиса для получения истории ссылки.
+type URLService interface {
+	History(ctx context.Context, userID, shortCode string) ([]model.URLHistoryEntry, error)
+}
+
+// New конструктор HandlerFunc для получения прежних адресов ссылки пользователя.
+func New(log *slog.Logger, svc URLService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "URLHistory.Get"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		log.Info("received request")
+
+		userID, ok := r.Context().Value(model.UserIDKey).(string)
+		if !ok || userID == "" {
+			log.Error("Unauthorized", "error", http.StatusText(http.StatusUnauthorized))
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		history, err := svc.History(r.Context(), userID, chi.URLParam(r, "shortCode"))
+		if status, message, ok := apierror.Status(err); ok {
+			http.Error(w, message, status)
+			return
+		}
+		if err != nil {
+			log.Error("service History", "error", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(history); err != nil {
+			log.Error("Encode response", "error", err)
+		}
+	}
+}
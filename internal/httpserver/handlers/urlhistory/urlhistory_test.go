@@ -0,0 +1,119 @@
+package urlhistory
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockURLService struct {
+	mock.Mock
+}
+
+func (m *MockURLService) History(ctx context.Context, userID, shortCode string) ([]model.URLHistoryEntry, error) {
+	args := m.Called(ctx, userID, shortCode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.URLHistoryEntry), args.Error(1)
+}
+
+func TestURLHistoryHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	changed := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		userID         string
+		mockFunc       func(m *MockURLService)
+		expectedStatus int
+		expectedBody   string
+		isJSONResponse bool
+	}{
+		{
+			name:   "Success",
+			userID: "1",
+			mockFunc: func(m *MockURLService) {
+				m.On("History", mock.Anything, "1", "abc").
+					Return([]model.URLHistoryEntry{{OriginalURL: "https://gogle.com/", ChangedAt: changed}}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"original_url":"https://gogle.com/","changed_at":"2025-01-02T03:04:05Z"}]`,
+			isJSONResponse: true,
+		},
+		{
+			name:           "Unauthorized",
+			mockFunc:       func(m *MockURLService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized\n",
+		},
+		{
+			name:   "NotFound",
+			userID: "2",
+			mockFunc: func(m *MockURLService) {
+				m.On("History", mock.Anything, "2", "abc").
+					Return(nil, model.ErrURLNotFound).
+					Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "url not found\n",
+		},
+		{
+			name:   "InternalError",
+			userID: "1",
+			mockFunc: func(m *MockURLService) {
+				m.On("History", mock.Anything, "1", "abc").
+					Return(nil, errors.New("database error")).
+					Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Internal Server Error\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc := new(MockURLService)
+			test.mockFunc(svc)
+
+			handler := New(logger, svc)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/user/urls/abc/history", nil)
+			routeCtx := chi.NewRouteContext()
+			routeCtx.URLParams.Add("shortCode", "abc")
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx)
+			if test.userID != "" {
+				ctx = context.WithValue(ctx, model.UserIDKey, test.userID)
+			}
+			req = req.WithContext(ctx)
+
+			w := httptest.NewRecorder()
+			handler(w, req)
+
+			resp := w.Result()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+			if test.isJSONResponse {
+				assert.JSONEq(t, test.expectedBody, string(body))
+			} else {
+				assert.Equal(t, test.expectedBody, string(body))
+			}
+			svc.AssertExpectations(t)
+		})
+	}
+}
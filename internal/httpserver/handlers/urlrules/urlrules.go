@@ -0,0 +1,104 @@
+// Package urlrules предоставляет правила перенаправления короткой ссылки её владельцу.
+package urlrules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/ArtShib/urlshortener/internal/httpserver/handlers/apierror"
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// URLService интерфейс сервиса для правил перенаправления ссылки.
+type URLService interface {
+	Rules(ctx context.Context, userID, shortCode string) ([]model.Rule, error)
+	UpdateRules(ctx context.Context, userID, shortCode string, rules []model.Rule) (*model.URL, error)
+}
+
+// New конструктор HandlerFunc для правил перенаправления ссылки.
+// GET отдаёт правила, PUT заменяет их списком из тела запроса: [{"url": "...", "device": "ios"}, ...],
+// пустой список удаляет правила. Правила проверяются по порядку, без совпадений переход идёт на адрес ссылки.
+func New(log *slog.Logger, svc URLService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "URLRules.Handle"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		log.Info("received request")
+
+		userID, ok := r.Context().Value(model.UserIDKey).(string)
+		if !ok || userID == "" {
+			log.Error("Unauthorized", "error", http.StatusText(http.StatusUnauthorized))
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		shortCode := chi.URLParam(r, "shortCode")
+
+		var (
+			rules []model.Rule
+			err   error
+		)
+		if r.Method == http.MethodPut {
+			rules, err = update(w, r, log, svc, userID, shortCode)
+		} else {
+			rules, err = svc.Rules(r.Context(), userID, shortCode)
+		}
+		if status, message, ok := apierror.Status(err); ok {
+			http.Error(w, message, status)
+			return
+		}
+		if err != nil {
+			log.Error("service Rules", "error", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(rules); err != nil {
+			log.Error("Encode response", "error", err)
+		}
+	}
+}
+
+// errBadBody тело запроса не является списком правил
+var errBadBody = model.NewRequestError(model.ErrInvalidRules, "body must be a JSON array of rules")
+
+// update заменяет правила ссылки и возвращает сохранённые правила
+func update(w http.ResponseWriter, r *http.Request, log *slog.Logger, svc URLService, userID, shortCode string) ([]model.Rule, error) {
+	model.AuditFromContext(r.Context()).Action = model.ActionEdit
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			log.Error("close body", "error", err)
+		}
+	}()
+
+	var rules []model.Rule
+	if err := json.NewDecoder(r.Body).Decode(&rules); err != nil || rules == nil {
+		if err != nil {
+			log.Error("Body decode", "error", err)
+		}
+		return nil, errBadBody
+	}
+
+	url, err := svc.UpdateRules(r.Context(), userID, shortCode, rules)
+	if err != nil {
+		if errors.Is(err, model.ErrURLBlocked) {
+			model.AuditFromContext(r.Context()).Action = model.ActionBlocked
+		}
+		return nil, err
+	}
+	model.AuditFromContext(r.Context()).OriginalURL = url.OriginalURL
+	if url.Rules == nil {
+		return []model.Rule{}, nil
+	}
+	return url.Rules, nil
+}
@@ -0,0 +1,184 @@
+package urlrules
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockURLService struct {
+	mock.Mock
+}
+
+func (m *MockURLService) Rules(ctx context.Context, userID, shortCode string) ([]model.Rule, error) {
+	args := m.Called(ctx, userID, shortCode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Rule), args.Error(1)
+}
+
+func (m *MockURLService) UpdateRules(ctx context.Context, userID, shortCode string, rules []model.Rule) (*model.URL, error) {
+	args := m.Called(ctx, userID, shortCode, rules)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.URL), args.Error(1)
+}
+
+func TestURLRulesHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ios := []model.Rule{{URL: "https://apps.apple.com/app/id1", Device: "ios"}}
+
+	tests := []struct {
+		name           string
+		method         string
+		userID         string
+		body           string
+		mockFunc       func(m *MockURLService)
+		expectedStatus int
+		expectedBody   string
+		expectedAction string
+		isJSONResponse bool
+	}{
+		{
+			name:   "Get",
+			method: http.MethodGet,
+			userID: "1",
+			mockFunc: func(m *MockURLService) {
+				m.On("Rules", mock.Anything, "1", "abc").Return(ios, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"url":"https://apps.apple.com/app/id1","device":"ios"}]`,
+			isJSONResponse: true,
+		},
+		{
+			name:   "Put",
+			method: http.MethodPut,
+			userID: "1",
+			body:   `[{"url":"https://apps.apple.com/app/id1","device":"iOS"}]`,
+			mockFunc: func(m *MockURLService) {
+				m.On("UpdateRules", mock.Anything, "1", "abc", []model.Rule{{URL: "https://apps.apple.com/app/id1", Device: "iOS"}}).
+					Return(&model.URL{UUID: "abc", OriginalURL: "https://example.com", Rules: ios}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"url":"https://apps.apple.com/app/id1","device":"ios"}]`,
+			expectedAction: model.ActionEdit,
+			isJSONResponse: true,
+		},
+		{
+			name:   "PutEmptyClears",
+			method: http.MethodPut,
+			userID: "1",
+			body:   `[]`,
+			mockFunc: func(m *MockURLService) {
+				m.On("UpdateRules", mock.Anything, "1", "abc", []model.Rule{}).
+					Return(&model.URL{UUID: "abc", OriginalURL: "https://example.com"}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[]`,
+			expectedAction: model.ActionEdit,
+			isJSONResponse: true,
+		},
+		{
+			name:           "PutNotArray",
+			method:         http.MethodPut,
+			userID:         "1",
+			body:           `{"url":"https://example.com"}`,
+			mockFunc:       func(m *MockURLService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid rules: body must be a JSON array of rules\n",
+			expectedAction: model.ActionEdit,
+		},
+		{
+			name:   "PutInvalidRule",
+			method: http.MethodPut,
+			userID: "1",
+			body:   `[{"url":"https://example.com","device":"fridge"}]`,
+			mockFunc: func(m *MockURLService) {
+				m.On("UpdateRules", mock.Anything, "1", "abc", mock.Anything).
+					Return(nil, model.NewRequestError(model.ErrInvalidRules, "rule 1: device must be one of ios, android")).
+					Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid rules: rule 1: device must be one of ios, android\n",
+			expectedAction: model.ActionEdit,
+		},
+		{
+			name:           "Unauthorized",
+			method:         http.MethodGet,
+			mockFunc:       func(m *MockURLService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized\n",
+		},
+		{
+			name:   "NotFound",
+			method: http.MethodGet,
+			userID: "2",
+			mockFunc: func(m *MockURLService) {
+				m.On("Rules", mock.Anything, "2", "abc").Return(nil, model.ErrURLNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "url not found\n",
+		},
+		{
+			name:   "InternalError",
+			method: http.MethodGet,
+			userID: "1",
+			mockFunc: func(m *MockURLService) {
+				m.On("Rules", mock.Anything, "1", "abc").Return(nil, errors.New("database error")).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Internal Server Error\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc := new(MockURLService)
+			test.mockFunc(svc)
+
+			handler := New(logger, svc)
+
+			req := httptest.NewRequest(test.method, "/api/user/urls/abc/rules", strings.NewReader(test.body))
+			routeCtx := chi.NewRouteContext()
+			routeCtx.URLParams.Add("shortCode", "abc")
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx)
+			if test.userID != "" {
+				ctx = context.WithValue(ctx, model.UserIDKey, test.userID)
+			}
+			ctx, audit := model.NewAuditContext(ctx)
+			req = req.WithContext(ctx)
+
+			w := httptest.NewRecorder()
+			handler(w, req)
+
+			resp := w.Result()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+			assert.Equal(t, test.expectedAction, audit.Action)
+			if test.isJSONResponse {
+				assert.JSONEq(t, test.expectedBody, string(body))
+			} else {
+				assert.Equal(t, test.expectedBody, string(body))
+			}
+			svc.AssertExpectations(t)
+		})
+	}
+}
@@ -22,22 +22,27 @@ func NewEvent(log *slog.Logger, svc ServiceEvent) func(next http.Handler) http.H
 				slog.String("op", op),
 			)
 
-			next.ServeHTTP(w, r)
+			ctx, audit := model.NewAuditContext(r.Context())
+			next.ServeHTTP(w, r.WithContext(ctx))
 
 			userID, ok := r.Context().Value(model.UserIDKey).(string)
 			if !ok || userID == "" {
 				logger.Error(op, "error", http.StatusText(http.StatusUnauthorized))
 			}
 
-			action := "shorten"
+			action := model.ActionShorten
 			if r.Method == http.MethodGet {
-				action = "follow"
+				action = model.ActionFollow
+			}
+			if audit.Action != "" {
+				action = audit.Action
 			}
 			event := &model.Event{
 				TimeStamp:   time.Now().Unix(),
 				Action:      action,
 				UserID:      userID,
-				OriginalURL: w.Header().Get("OriginalURL"),
+				OriginalURL: audit.OriginalURL,
+				Variant:     audit.Variant,
 			}
 
 			svc.AddEventRecord(event)
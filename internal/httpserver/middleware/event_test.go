@@ -1 +1,101 @@
 package middleware
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type eventSpy struct {
+	events []*model.Event
+}
+
+func (s *eventSpy) AddEventRecord(event *model.Event) {
+	s.events = append(s.events, event)
+}
+
+func TestEventMiddleware(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name            string
+		method          string
+		handler         http.HandlerFunc
+		expectedAction  string
+		expectedURL     string
+		expectedVariant string
+	}{
+		{
+			name:           "DefaultFollow",
+			method:         http.MethodGet,
+			handler:        func(w http.ResponseWriter, r *http.Request) {},
+			expectedAction: model.ActionFollow,
+		},
+		{
+			name:           "DefaultShorten",
+			method:         http.MethodPost,
+			handler:        func(w http.ResponseWriter, r *http.Request) {},
+			expectedAction: model.ActionShorten,
+		},
+		{
+			name:   "HandlerAction",
+			method: http.MethodGet,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				model.AuditFromContext(r.Context()).Action = model.ActionBlocked
+				w.WriteHeader(http.StatusForbidden)
+			},
+			expectedAction: model.ActionBlocked,
+		},
+		{
+			name:   "HandlerURL",
+			method: http.MethodPost,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				model.AuditFromContext(r.Context()).OriginalURL = "https://google.com"
+				w.WriteHeader(http.StatusCreated)
+			},
+			expectedAction: model.ActionShorten,
+			expectedURL:    "https://google.com",
+		},
+		{
+			name:   "HandlerVariant",
+			method: http.MethodGet,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				audit := model.AuditFromContext(r.Context())
+				audit.OriginalURL = "https://example.com/b"
+				audit.Variant = "b"
+				w.WriteHeader(http.StatusTemporaryRedirect)
+			},
+			expectedAction:  model.ActionFollow,
+			expectedURL:     "https://example.com/b",
+			expectedVariant: "b",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spy := &eventSpy{}
+			handler := NewEvent(logger, spy)(test.handler)
+
+			req := httptest.NewRequest(test.method, "/abc", nil)
+			req = req.WithContext(context.WithValue(req.Context(), model.UserIDKey, "1"))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			require.Len(t, spy.events, 1)
+			assert.Equal(t, test.expectedAction, spy.events[0].Action)
+			assert.Equal(t, test.expectedURL, spy.events[0].OriginalURL)
+			assert.Equal(t, test.expectedVariant, spy.events[0].Variant)
+			assert.Equal(t, "1", spy.events[0].UserID)
+			assert.Empty(t, w.Header().Get("AuditAction"), "audit details are not sent to the client")
+			assert.Empty(t, w.Header().Get("OriginalURL"), "audit details are not sent to the client")
+			assert.Empty(t, w.Header().Get("AuditVariant"), "audit details are not sent to the client")
+		})
+	}
+}
@@ -52,3 +52,13 @@ type gzipResponseWriter struct {
 func (w gzipResponseWriter) Write(b []byte) (int, error) {
 	return w.Writer.Write(b)
 }
+
+// Flush отправляет клиенту сжатые данные, накопленные gzip.Writer, для потоковых ответов
+func (w gzipResponseWriter) Flush() {
+	if err := w.Writer.Flush(); err != nil {
+		return
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -1 +1,39 @@
 package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGzipMiddleware_Flush(t *testing.T) {
+	var flushed int
+	rec := httptest.NewRecorder()
+	handler := GzipMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, err := io.WriteString(w, `{"part":1}`)
+		require.NoError(t, err)
+		require.NoError(t, http.NewResponseController(w).Flush())
+		flushed = rec.Body.Len()
+		_, err = io.WriteString(w, `{"part":2}`)
+		require.NoError(t, err)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/user/urls/export", nil)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	handler.ServeHTTP(rec, req)
+
+	assert.Positive(t, flushed, "flush sends compressed data before the handler returns")
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	reader, err := gzip.NewReader(rec.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, `{"part":1}{"part":2}`, string(body))
+}
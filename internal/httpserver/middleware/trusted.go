@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+)
+
+// RealIPHeader заголовок с адресом клиента, его выставляет обратный прокси
+const RealIPHeader = "X-Real-IP"
+
+// TrustedSubnet конструктор middleware, пропускающего только клиентов из доверенной подсети.
+// Адрес клиента берётся из X-Real-IP. Без подсети доступ закрыт для всех
+func TrustedSubnet(subnet *net.IPNet, log *slog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			const op = "middleware.TrustedSubnet"
+
+			ip := net.ParseIP(r.Header.Get(RealIPHeader))
+			if subnet == nil || ip == nil || !subnet.Contains(ip) {
+				log.Warn(op, "error", "client is not in the trusted subnet", "ip", r.Header.Get(RealIPHeader))
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustedSubnet(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	_, subnet, err := net.ParseCIDR("192.168.1.0/24")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		subnet         *net.IPNet
+		realIP         string
+		expectedStatus int
+	}{
+		{name: "Trusted", subnet: subnet, realIP: "192.168.1.10", expectedStatus: http.StatusOK},
+		{name: "OutsideSubnet", subnet: subnet, realIP: "10.0.0.1", expectedStatus: http.StatusForbidden},
+		{name: "NoHeader", subnet: subnet, expectedStatus: http.StatusForbidden},
+		{name: "MalformedHeader", subnet: subnet, realIP: "192.168.1", expectedStatus: http.StatusForbidden},
+		{name: "NoSubnet", realIP: "192.168.1.10", expectedStatus: http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := TrustedSubnet(test.subnet, logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/stats", nil)
+			if test.realIP != "" {
+				req.Header.Set(RealIPHeader, test.realIP)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatus, w.Code)
+		})
+	}
+}
@@ -3,15 +3,27 @@ package httpserver
 import (
 	"context"
 	"log/slog"
+	"net"
 	"net/http"
+	"slices"
+	"strings"
 
+	"github.com/ArtShib/urlshortener/internal/httpserver/handlers/clickstats"
 	"github.com/ArtShib/urlshortener/internal/httpserver/handlers/deleteurls"
+	"github.com/ArtShib/urlshortener/internal/httpserver/handlers/editurl"
+	"github.com/ArtShib/urlshortener/internal/httpserver/handlers/exporturls"
 	"github.com/ArtShib/urlshortener/internal/httpserver/handlers/getid"
 	"github.com/ArtShib/urlshortener/internal/httpserver/handlers/getjsonbatch"
+	"github.com/ArtShib/urlshortener/internal/httpserver/handlers/importurls"
 	"github.com/ArtShib/urlshortener/internal/httpserver/handlers/ping"
+	"github.com/ArtShib/urlshortener/internal/httpserver/handlers/qr"
+	"github.com/ArtShib/urlshortener/internal/httpserver/handlers/restoreurls"
 	"github.com/ArtShib/urlshortener/internal/httpserver/handlers/shorten"
 	"github.com/ArtShib/urlshortener/internal/httpserver/handlers/shortenjson"
 	"github.com/ArtShib/urlshortener/internal/httpserver/handlers/shortenjsonbatch"
+	"github.com/ArtShib/urlshortener/internal/httpserver/handlers/stats"
+	"github.com/ArtShib/urlshortener/internal/httpserver/handlers/urlhistory"
+	"github.com/ArtShib/urlshortener/internal/httpserver/handlers/urlrules"
 	customMiddleware "github.com/ArtShib/urlshortener/internal/httpserver/middleware"
 	"github.com/ArtShib/urlshortener/internal/lib/auth"
 	"github.com/ArtShib/urlshortener/internal/model"
@@ -21,26 +33,49 @@ import (
 
 // URLService описывает интерфейс сокращения url
 type URLService interface {
-	Shorten(ctx context.Context, url string) (string, error)
+	Shorten(ctx context.Context, req model.RequestShortener) (string, error)
 	GetID(ctx context.Context, shortCode string) (*model.URL, error)
-	ShortenJSON(ctx context.Context, url string) (*model.ResponseShortener, error)
+	ConsumeClick(ctx context.Context, shortCode string) (bool, error)
+	Unlock(ctx context.Context, url *model.URL, password string) error
+	CheckTarget(ctx context.Context, originalURL string) error
+	ShortenJSON(ctx context.Context, req model.RequestShortener) (*model.ResponseShortener, error)
 	Ping(ctx context.Context) error
+	Stats(ctx context.Context) (*model.RepositoryStats, error)
 	ShortenJSONBatch(ctx context.Context, urls model.RequestShortenerBatchArray) (model.ResponseShortenerBatchArray, error)
-	GetJSONBatch(ctx context.Context, userID string) (model.URLUserBatch, error)
+	GetJSONBatch(ctx context.Context, filter model.URLUserFilter) (*model.URLUserPage, error)
+	ImportURLs(ctx context.Context, rows []model.ImportRow) []model.ImportResult
+	ExportURLs(ctx context.Context, userID string, yield func(model.URLExport) error) error
+	ClickStats(ctx context.Context, req model.ClickStatsRequest) (*model.ClickStats, error)
+	UpdateURL(ctx context.Context, userID, shortCode string, req model.RequestEditURL) (*model.URL, error)
+	History(ctx context.Context, userID, shortCode string) ([]model.URLHistoryEntry, error)
+	Rules(ctx context.Context, userID, shortCode string) ([]model.Rule, error)
+	UpdateRules(ctx context.Context, userID, shortCode string, rules []model.Rule) (*model.URL, error)
+	ReserveAliases(names ...string)
 }
 
-// WorkerPoolDelete описывает интерфейс удаления url
+// WorkerPoolDelete описывает интерфейс удаления и восстановления url
 type WorkerPoolDelete interface {
 	AddRequest(req model.DeleteRequest)
 }
 
+// ClickRecorder описывает интерфейс записи переходов
+type ClickRecorder interface {
+	AddClick(click model.Click)
+}
+
+// Purger описывает интерфейс результатов очистки удалённых ссылок
+type Purger interface {
+	Stats() model.PurgeStats
+}
+
 // ServiceEvent описывает интерфейс сохранения аудита
 type ServiceEvent interface {
 	AddEventRecord(event *model.Event)
 }
 
-// NewRouter конструктор Router
-func NewRouter(svc URLService, log *slog.Logger, auth *auth.Service, poolDel WorkerPoolDelete, eventSvc ServiceEvent) http.Handler {
+// NewRouter конструктор Router. trusted подсеть, из которой доступна статистика хранилища, nil закрывает её.
+// imports ограничения загрузки ссылок
+func NewRouter(svc URLService, log *slog.Logger, auth *auth.Service, poolDel WorkerPoolDelete, eventSvc ServiceEvent, clickRec ClickRecorder, purge Purger, trusted *net.IPNet, imports model.ImportLimits) http.Handler {
 
 	mux := chi.NewRouter()
 	mux.Use(customMiddleware.Auth(auth, log))
@@ -52,15 +87,41 @@ func NewRouter(svc URLService, log *slog.Logger, auth *auth.Service, poolDel Wor
 	mux.Route("/api/user", func(r chi.Router) {
 		r.Get("/urls", getjsonbatch.New(log, svc))
 		r.Delete("/urls", deleteurls.New(log, poolDel))
+		r.Post("/urls/restore", restoreurls.New(log, poolDel))
+		r.Post("/urls/import", importurls.New(log, svc, imports))
+		r.Get("/urls/export", exporturls.New(log, svc))
+		r.Get("/urls/{shortCode}/stats", clickstats.New(log, svc))
+		r.Get("/urls/{shortCode}/history", urlhistory.New(log, svc))
+		r.Get("/urls/{shortCode}/rules", urlrules.New(log, svc))
+		r.With(customMiddleware.NewEvent(log, eventSvc)).Put("/urls/{shortCode}/rules", urlrules.New(log, svc))
+		r.With(customMiddleware.NewEvent(log, eventSvc)).Patch("/urls/{shortCode}", editurl.New(log, svc))
 	})
+	mux.With(customMiddleware.TrustedSubnet(trusted, log)).Get("/api/stats", stats.New(log, svc, purge))
 	mux.Get("/ping", ping.New(log, svc))
+	mux.Get("/{shortCode}/qr", qr.New(log, svc))
 	mux.Group(func(r chi.Router) {
 		r.Use(customMiddleware.NewEvent(log, eventSvc))
 		r.Post("/", shorten.New(log, svc))
 		r.Post("/api/shorten", shortenjson.New(log, svc))
 		r.Post("/api/shorten/batch", shortenjsonbatch.New(log, svc))
-		r.Get("/{shortCode}", getid.New(log, svc))
+		r.Get("/{shortCode}", getid.New(log, svc, clickRec))
+		r.Post("/{shortCode}", getid.New(log, svc, clickRec))
 	})
 
+	// короткая ссылка с alias, совпадающим с маршрутом, была бы недоступна
+	svc.ReserveAliases(TopLevelSegments(mux)...)
 	return mux
 }
+
+// TopLevelSegments первые сегменты путей роутера, кроме параметров вида {shortCode}
+func TopLevelSegments(routes chi.Routes) []string {
+	var segments []string
+	_ = chi.Walk(routes, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		segment, _, _ := strings.Cut(strings.TrimPrefix(route, "/"), "/")
+		if segment != "" && !strings.HasPrefix(segment, "{") && !slices.Contains(segments, segment) {
+			segments = append(segments, segment)
+		}
+		return nil
+	})
+	return segments
+}
@@ -0,0 +1,188 @@
+// Package blocklist проверяет url по файлу заблокированных доменов и регулярных выражений.
+//
+// Формат файла: одна запись на строку, пустые строки и строки с # пропускаются.
+// Домен блокирует себя и все поддомены, строка с префиксом re: — регулярное выражение для всего url.
+package blocklist
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+const regexpPrefix = "re:"
+
+// rules разобранное содержимое файла
+type rules struct {
+	domains  map[string]struct{}
+	patterns []*regexp.Regexp
+}
+
+// Blocklist файловый список блокировок, перечитывается при изменении файла
+type Blocklist struct {
+	path     string
+	interval time.Duration
+	logger   *slog.Logger
+
+	mu      sync.RWMutex
+	rules   *rules
+	modTime time.Time
+	size    int64
+
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+// New конструктор Blocklist, сразу читает файл
+func New(path string, interval time.Duration, log *slog.Logger) (*Blocklist, error) {
+	const op = "blocklist.New"
+	b := &Blocklist{
+		path:     path,
+		interval: interval,
+		logger:   log,
+	}
+	if err := b.load(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return b, nil
+}
+
+// Start запускает отслеживание изменений файла
+func (b *Blocklist) Start(ctx context.Context) {
+	if b.interval <= 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	b.cancel = cancel
+
+	b.wg.Add(1)
+	go b.watch(ctx)
+}
+
+// Stop останавливает отслеживание изменений файла
+func (b *Blocklist) Stop() {
+	if b.cancel != nil {
+		b.cancel()
+	}
+	b.wg.Wait()
+}
+
+func (b *Blocklist) watch(ctx context.Context) {
+	const op = "blocklist.watch"
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := b.reloadIfChanged(); err != nil {
+				b.logger.Error(op, "error", err, "path", b.path)
+			}
+		}
+	}
+}
+
+// reloadIfChanged перечитывает файл, если изменились время модификации или размер.
+// При ошибке остаётся предыдущий список
+func (b *Blocklist) reloadIfChanged() error {
+	info, err := os.Stat(b.path)
+	if err != nil {
+		return err
+	}
+	b.mu.RLock()
+	changed := !info.ModTime().Equal(b.modTime) || info.Size() != b.size
+	b.mu.RUnlock()
+	if !changed {
+		return nil
+	}
+	if err := b.load(); err != nil {
+		return err
+	}
+	b.logger.Info("blocklist reloaded", "path", b.path)
+	return nil
+}
+
+func (b *Blocklist) load() error {
+	info, err := os.Stat(b.path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(b.path)
+	if err != nil {
+		return err
+	}
+	parsed, err := parse(data)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rules = parsed
+	b.modTime = info.ModTime()
+	b.size = info.Size()
+	return nil
+}
+
+func parse(data []byte) (*rules, error) {
+	parsed := &rules{domains: make(map[string]struct{})}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		if pattern, ok := strings.CutPrefix(entry, regexpPrefix); ok {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			parsed.patterns = append(parsed.patterns, re)
+			continue
+		}
+		parsed.domains[strings.TrimSuffix(strings.ToLower(entry), ".")] = struct{}{}
+	}
+	return parsed, scanner.Err()
+}
+
+// Check реализует service.URLChecker: возвращает model.ErrURLBlocked с причиной для заблокированного url
+func (b *Blocklist) Check(ctx context.Context, originalURL string) error {
+	u, err := url.Parse(originalURL)
+	if err != nil {
+		return nil
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	// домен и все его родительские домены
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	for domain := host; domain != ""; {
+		if _, ok := b.rules.domains[domain]; ok {
+			return model.NewRequestError(model.ErrURLBlocked, fmt.Sprintf("domain %s is blocked", domain))
+		}
+		_, parent, found := strings.Cut(domain, ".")
+		if !found {
+			break
+		}
+		domain = parent
+	}
+	for _, re := range b.rules.patterns {
+		if re.MatchString(originalURL) {
+			return model.NewRequestError(model.ErrURLBlocked, "url matches a blocked pattern")
+		}
+	}
+	return nil
+}
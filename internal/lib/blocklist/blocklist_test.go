@@ -0,0 +1,65 @@
+package blocklist
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlocklist_Check(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# phishing\nevil.com\n\nre:^https?://[^/]+/login\\.php\n"), 0644))
+
+	b, err := New(path, 0, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+
+	tests := []struct {
+		url     string
+		blocked bool
+	}{
+		{url: "https://evil.com/", blocked: true},
+		{url: "https://login.EVIL.com/a", blocked: true},
+		{url: "https://notevil.com/", blocked: false},
+		{url: "https://bank.example/login.php", blocked: true},
+		{url: "https://google.com/", blocked: false},
+	}
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			err := b.Check(context.Background(), test.url)
+			if test.blocked {
+				assert.ErrorIs(t, err, model.ErrURLBlocked)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBlocklist_Reload(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	require.NoError(t, os.WriteFile(path, []byte("evil.com\n"), 0644))
+
+	b, err := New(path, 0, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	assert.NoError(t, b.Check(ctx, "https://bad.org/"))
+
+	require.NoError(t, os.WriteFile(path, []byte("evil.com\nbad.org\n"), 0644))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+	require.NoError(t, b.reloadIfChanged())
+	assert.ErrorIs(t, b.Check(ctx, "https://bad.org/"), model.ErrURLBlocked)
+
+	// некорректный файл не сбрасывает действующий список
+	require.NoError(t, os.WriteFile(path, []byte("re:(\n"), 0644))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(2*time.Second)))
+	assert.Error(t, b.reloadIfChanged())
+	assert.ErrorIs(t, b.Check(ctx, "https://evil.com/"), model.ErrURLBlocked)
+}
@@ -0,0 +1,23 @@
+package password
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashVerify(t *testing.T) {
+	hash, err := Hash("secret")
+	require.NoError(t, err)
+	assert.NotContains(t, hash, "secret")
+
+	other, err := Hash("secret")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other, "salt must differ between hashes")
+
+	assert.True(t, Verify(hash, "secret"))
+	assert.False(t, Verify(hash, "Secret"))
+	assert.False(t, Verify("", "secret"))
+	assert.False(t, Verify("plain$1$a$b", "secret"))
+}
@@ -0,0 +1,208 @@
+package qrcode
+
+import (
+	"bytes"
+	"image/png"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReedSolomon(t *testing.T) {
+	// HELLO WORLD, версия 1-M из примера thonky.com
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	assert.Equal(t, want, rsRemainder(data, rsDivisor(len(want))))
+}
+
+func TestFormatAndVersionWords(t *testing.T) {
+	assert.Equal(t, 0b101010000010010, formatWord(LevelM, 0))
+	assert.Equal(t, 0b110011000101111, formatWord(LevelL, 4))
+	assert.Equal(t, 0x07C94, versionWord(7))
+	assert.Equal(t, 0x28C69, versionWord(40))
+}
+
+func TestAlignmentPositions(t *testing.T) {
+	assert.Nil(t, alignmentPositions(1))
+	assert.Equal(t, []int{6, 18}, alignmentPositions(2))
+	assert.Equal(t, []int{6, 22, 38}, alignmentPositions(7))
+	assert.Equal(t, []int{6, 34, 60, 86, 112, 138}, alignmentPositions(32))
+	assert.Equal(t, []int{6, 30, 58, 86, 114, 142, 170}, alignmentPositions(40))
+}
+
+func TestCapacity(t *testing.T) {
+	// наибольшая длина данных в байтовом режиме из таблиц стандарта
+	tests := []struct {
+		version int
+		level   Level
+		bytes   int
+	}{
+		{1, LevelL, 17}, {1, LevelM, 14}, {1, LevelQ, 11}, {1, LevelH, 7},
+		{10, LevelL, 271}, {10, LevelM, 213}, {10, LevelQ, 151}, {10, LevelH, 119},
+		{40, LevelL, 2953}, {40, LevelM, 2331}, {40, LevelQ, 1663}, {40, LevelH, 1273},
+	}
+	for _, test := range tests {
+		assert.LessOrEqual(t, dataBits(test.version, test.bytes), numDataCodewords(test.version, test.level)*8)
+		assert.Greater(t, dataBits(test.version, test.bytes+1), numDataCodewords(test.version, test.level)*8)
+	}
+
+	_, err := Encode(make([]byte, 2954), LevelL)
+	assert.ErrorIs(t, err, ErrTooLong)
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	tests := []struct {
+		data    string
+		level   Level
+		version int
+	}{
+		{data: "http://localhost:8080/a7v4M9PY", level: LevelM, version: 3},
+		{data: "https://s.example.com/spring-sale", level: LevelH, version: 4},
+		{data: strings.Repeat("https://example.com/", 20), level: LevelQ, version: 19},
+		{data: strings.Repeat("x", 2331), level: LevelM, version: 40},
+	}
+	for _, test := range tests {
+		code, err := Encode([]byte(test.data), test.level)
+		require.NoError(t, err)
+		assert.Equal(t, test.version, code.Version)
+		assert.Equal(t, test.data, string(decode(t, code)))
+	}
+}
+
+// decode читает код обратно: строку формата, маску, кодовые слова, проверяет коды коррекции и разбирает сегмент
+func decode(t *testing.T, code *Code) []byte {
+	t.Helper()
+	size := code.Size()
+
+	format := 0
+	for i := 0; i <= 5; i++ {
+		format |= b2i(code.Dark(8, i)) << i
+	}
+	format |= b2i(code.Dark(8, 7))<<6 | b2i(code.Dark(8, 8))<<7 | b2i(code.Dark(7, 8))<<8
+	for i := 9; i < 15; i++ {
+		format |= b2i(code.Dark(14-i, 8)) << i
+	}
+	mask := (format ^ formatMask) >> 10 & 7
+	require.Equal(t, formatWord(code.Level, mask), format, "format bits")
+
+	layout := &Code{Version: code.Version, Level: code.Level, size: size,
+		modules: make([]bool, size*size), function: make([]bool, size*size)}
+	layout.drawFunctionPatterns()
+
+	var bits bitBuffer
+	for right := size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := range size {
+			y := vert
+			if upward {
+				y = size - 1 - vert
+			}
+			for j := range 2 {
+				x := right - j
+				if layout.function[y*size+x] {
+					continue
+				}
+				bits = append(bits, code.Dark(x, y) != maskDark(mask, x, y))
+			}
+		}
+	}
+	raw := bits.bytes()[:numRawDataModules(code.Version)/8]
+
+	numBlocks := eccBlocks[code.Level][code.Version]
+	eccLen := eccPerBlock[code.Level][code.Version]
+	numShort := numBlocks - len(raw)%numBlocks
+	shortLen := len(raw) / numBlocks
+	blocks := make([][]byte, numBlocks)
+	k := 0
+	for i := 0; i <= shortLen; i++ {
+		for j := range blocks {
+			// у коротких блоков нет кодового слова на месте выравнивания
+			if i == shortLen-eccLen && j < numShort {
+				continue
+			}
+			blocks[j] = append(blocks[j], raw[k])
+			k++
+		}
+	}
+	var data []byte
+	for _, block := range blocks {
+		dat, ecc := block[:len(block)-eccLen], block[len(block)-eccLen:]
+		require.Equal(t, ecc, rsRemainder(dat, rsDivisor(eccLen)), "error correction codewords")
+		data = append(data, dat...)
+	}
+
+	reader := bitReader{data: data}
+	require.Equal(t, modeByte, reader.read(4))
+	length := reader.read(countBits(code.Version))
+	result := make([]byte, length)
+	for i := range result {
+		result[i] = byte(reader.read(8))
+	}
+	return result
+}
+
+type bitReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *bitReader) read(n int) int {
+	result := 0
+	for range n {
+		result = result<<1 | int(r.data[r.pos>>3]>>(7-r.pos&7)&1)
+		r.pos++
+	}
+	return result
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func TestParseOptions(t *testing.T) {
+	opts, err := ParseOptions(url.Values{})
+	require.NoError(t, err)
+	assert.Equal(t, Options{Format: FormatPNG, Size: 256, Level: LevelM, Margin: 4}, opts)
+
+	opts, err = ParseOptions(url.Values{"format": {"SVG"}, "size": {"512"}, "level": {"h"}, "margin": {"0"}})
+	require.NoError(t, err)
+	assert.Equal(t, Options{Format: FormatSVG, Size: 512, Level: LevelH, Margin: 0}, opts)
+	assert.Equal(t, "image/svg+xml", opts.ContentType())
+
+	for _, query := range []url.Values{
+		{"format": {"gif"}}, {"size": {"10"}}, {"size": {"big"}}, {"level": {"X"}}, {"level": {"LM"}}, {"margin": {"-1"}},
+	} {
+		_, err := ParseOptions(query)
+		assert.ErrorIs(t, err, model.ErrInvalidQROptions, query.Encode())
+	}
+}
+
+func TestRender(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Render(&buf, "http://localhost:8080/abc", Options{Format: FormatPNG, Size: 100, Level: LevelL, Margin: 4}))
+	img, err := png.Decode(&buf)
+	require.NoError(t, err)
+	// версия 2: 25 модулей и поля по 4, по 3 пикселя на модуль
+	assert.Equal(t, 99, img.Bounds().Dx())
+	r, _, _, _ := img.At(4*3, 4*3).RGBA()
+	assert.Zero(t, r, "finder pattern corner is dark")
+	r, _, _, _ = img.At(0, 0).RGBA()
+	assert.NotZero(t, r, "margin is light")
+
+	buf.Reset()
+	require.NoError(t, Render(&buf, "http://localhost:8080/abc", Options{Format: FormatSVG, Size: 100, Level: LevelL, Margin: 4}))
+	svg := buf.String()
+	assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="100" height="100" viewBox="0 0 33 33"`))
+	assert.Contains(t, svg, "M4 4h7v1h-7z")
+	assert.True(t, strings.HasSuffix(svg, `"/></svg>`))
+}
@@ -0,0 +1,136 @@
+package qrcode
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// Форматы изображения
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+)
+
+const (
+	defaultSize   = 256
+	minSize       = 64
+	maxSize       = 2048
+	defaultMargin = 4
+	maxMargin     = 16
+)
+
+// Options параметры изображения QR кода. Size ширина в пикселях, Margin поле в модулях
+type Options struct {
+	Format string
+	Size   int
+	Level  Level
+	Margin int
+}
+
+// ParseOptions читает параметры format (png|svg), size, level (L|M|Q|H) и margin из query string
+func ParseOptions(query url.Values) (Options, error) {
+	opts := Options{Format: FormatPNG, Size: defaultSize, Level: LevelM, Margin: defaultMargin}
+	switch format := strings.ToLower(query.Get("format")); format {
+	case "":
+	case FormatPNG, FormatSVG:
+		opts.Format = format
+	default:
+		return opts, model.NewRequestError(model.ErrInvalidQROptions, "format must be png or svg")
+	}
+	if raw := query.Get("size"); raw != "" {
+		size, err := strconv.Atoi(raw)
+		if err != nil || size < minSize || size > maxSize {
+			return opts, model.NewRequestError(model.ErrInvalidQROptions, fmt.Sprintf("size must be between %d and %d", minSize, maxSize))
+		}
+		opts.Size = size
+	}
+	if raw := query.Get("level"); raw != "" {
+		level := strings.Index("LMQH", strings.ToUpper(raw))
+		if len(raw) != 1 || level < 0 {
+			return opts, model.NewRequestError(model.ErrInvalidQROptions, "level must be L, M, Q or H")
+		}
+		opts.Level = Level(level)
+	}
+	if raw := query.Get("margin"); raw != "" {
+		margin, err := strconv.Atoi(raw)
+		if err != nil || margin < 0 || margin > maxMargin {
+			return opts, model.NewRequestError(model.ErrInvalidQROptions, fmt.Sprintf("margin must be between 0 and %d", maxMargin))
+		}
+		opts.Margin = margin
+	}
+	return opts, nil
+}
+
+// ContentType MIME тип изображения
+func (o Options) ContentType() string {
+	if o.Format == FormatSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// Render кодирует text и пишет изображение в w
+func Render(w io.Writer, text string, opts Options) error {
+	code, err := Encode([]byte(text), opts.Level)
+	if err != nil {
+		return err
+	}
+	if opts.Format == FormatSVG {
+		return code.SVG(w, opts.Size, opts.Margin)
+	}
+	return code.PNG(w, opts.Size, opts.Margin)
+}
+
+// PNG рисует код в PNG. Модуль занимает целое число пикселей, поэтому изображение может быть
+// меньше size, но не меньше одного пикселя на модуль
+func (c *Code) PNG(w io.Writer, size, margin int) error {
+	total := c.size + 2*margin
+	scale := max(1, size/total)
+	img := image.NewPaletted(image.Rect(0, 0, total*scale, total*scale), color.Palette{color.White, color.Black})
+	for y := range c.size {
+		for x := range c.size {
+			if !c.Dark(x, y) {
+				continue
+			}
+			for py := (y + margin) * scale; py < (y+margin+1)*scale; py++ {
+				row := img.Pix[py*img.Stride:]
+				for px := (x + margin) * scale; px < (x+margin+1)*scale; px++ {
+					row[px] = 1
+				}
+			}
+		}
+	}
+	return png.Encode(w, img)
+}
+
+// SVG рисует код в SVG размером size, тёмные модули строки объединяются в один прямоугольник
+func (c *Code) SVG(w io.Writer, size, margin int) error {
+	total := c.size + 2*margin
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		size, size, total, total)
+	fmt.Fprintf(bw, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, total, total)
+	for y := range c.size {
+		for x := 0; x < c.size; x++ {
+			if !c.Dark(x, y) {
+				continue
+			}
+			start := x
+			for x < c.size && c.Dark(x, y) {
+				x++
+			}
+			fmt.Fprintf(bw, "M%d %dh%dv1h-%dz", start+margin, y+margin, x-start, x-start)
+		}
+	}
+	bw.WriteString(`"/></svg>`)
+	return bw.Flush()
+}
@@ -0,0 +1,76 @@
+// Package ratelimit ограничивает число неудачных попыток по ключу в скользящем окне.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// pruneThreshold размер, после которого из памяти вычищаются устаревшие ключи
+const pruneThreshold = 1024
+
+// Limiter считает неудачные попытки по ключу. Счётчик ключа сбрасывается,
+// когда с первой неудачи прошло больше window.
+type Limiter struct {
+	mu       sync.Mutex
+	max      int
+	window   time.Duration
+	failures map[string]*failure
+	now      func() time.Time
+}
+
+type failure struct {
+	count int
+	start time.Time
+}
+
+// New конструктор Limiter. max <= 0 отключает ограничение.
+func New(max int, window time.Duration) *Limiter {
+	return &Limiter{
+		max:      max,
+		window:   window,
+		failures: make(map[string]*failure),
+		now:      time.Now,
+	}
+}
+
+// Take резервирует попытку по ключу и сообщает, разрешена ли она. Попытка сразу считается неудачной,
+// успешную отменяет Reset, поэтому параллельные попытки не проходят мимо ограничения
+func (l *Limiter) Take(key string) bool {
+	if l.max <= 0 {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if len(l.failures) >= pruneThreshold {
+		l.prune(now)
+	}
+	f, ok := l.failures[key]
+	if !ok || now.Sub(f.start) > l.window {
+		l.failures[key] = &failure{count: 1, start: now}
+		return true
+	}
+	if f.count >= l.max {
+		return false
+	}
+	f.count++
+	return true
+}
+
+// Reset сбрасывает счётчик ключа после успешной попытки, возвращая и её саму
+func (l *Limiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.failures, key)
+}
+
+// prune удаляет ключи с истёкшим окном, вызывается под блокировкой
+func (l *Limiter) prune(now time.Time) {
+	for key, f := range l.failures {
+		if now.Sub(f.start) > l.window {
+			delete(l.failures, key)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package ratelimit
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Now()
+	limiter := New(2, time.Minute)
+	limiter.now = func() time.Time { return now }
+
+	assert.True(t, limiter.Take("aaa"))
+	assert.True(t, limiter.Take("aaa"))
+	assert.False(t, limiter.Take("aaa"))
+	assert.True(t, limiter.Take("bbb"), "limit is per key")
+
+	now = now.Add(2 * time.Minute)
+	assert.True(t, limiter.Take("aaa"), "window has passed")
+
+	limiter.Reset("aaa")
+	assert.True(t, limiter.Take("aaa"))
+	assert.True(t, limiter.Take("aaa"), "reset returns the successful attempt")
+	assert.False(t, limiter.Take("aaa"))
+}
+
+func TestLimiter_Concurrent(t *testing.T) {
+	limiter := New(5, time.Minute)
+
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if limiter.Take("aaa") {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.EqualValues(t, 5, allowed.Load(), "parallel attempts must not exceed the limit")
+}
@@ -1,30 +1,195 @@
 package shortener
 
 import (
+	"context"
 	"crypto/rand"
-	"encoding/base64"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// Стратегии генерации короткого кода
+const (
+	StrategyRandom  = "random"
+	StrategyCounter = "counter"
+	StrategyHash    = "hash"
 )
 
+// DefaultAlphabet алфавит кода по умолчанию (base64url)
+const DefaultAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+
+// base62Alphabet алфавит стратегии counter по умолчанию
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// Sequence источник монотонно растущих номеров для стратегии counter
+type Sequence interface {
+	NextSequence(ctx context.Context) (int64, error)
+}
+
 // Shortener структура сервиса Shortener
-type Shortener struct{}
+type Shortener struct {
+	strategy string
+	alphabet string
+	sequence Sequence
+	generate func(s *Shortener, ctx context.Context, originalURL string, length int, attempt int) (string, error)
+}
 
-// NewShortener конструктор Shortener
-func NewShortener() *Shortener {
-	return &Shortener{}
+// NewShortener конструктор Shortener. Пустые strategy и alphabet означают random и DefaultAlphabet
+// (base62 для counter), sequence нужна только стратегии counter
+func NewShortener(strategy string, alphabet string, sequence Sequence) (*Shortener, error) {
+	if strategy == "" {
+		strategy = StrategyRandom
+	}
+	if alphabet == "" {
+		alphabet = DefaultAlphabet
+		if strategy == StrategyCounter {
+			alphabet = base62Alphabet
+		}
+	}
+	if err := validateAlphabet(alphabet); err != nil {
+		return nil, err
+	}
+
+	s := &Shortener{
+		strategy: strategy,
+		alphabet: alphabet,
+		sequence: sequence,
+	}
+	switch strategy {
+	case StrategyRandom:
+		s.generate = (*Shortener).random
+	case StrategyHash:
+		s.generate = (*Shortener).hash
+	case StrategyCounter:
+		if sequence == nil {
+			return nil, errors.New("counter strategy requires a sequence")
+		}
+		s.generate = (*Shortener).counter
+	default:
+		return nil, fmt.Errorf("unknown code strategy %q", strategy)
+	}
+	return s, nil
 }
 
-// GenerateUUID генерация uuid
-func (s Shortener) GenerateUUID() (string, error) {
-	lenUUID := 8
-	bytes := make([]byte, lenUUID)
-	_, err := rand.Read(bytes)
-	if err != nil {
-		return "", err
+// unreservedChars символы, которые не нужно кодировать в пути url (RFC 3986). Код из других символов
+// разрезается или обрезается при маршрутизации
+const unreservedChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-._~"
+
+func validateAlphabet(alphabet string) error {
+	if len(alphabet) < 2 {
+		return fmt.Errorf("alphabet must contain at least 2 characters")
+	}
+	if strings.Contains(alphabet, model.PreviewSuffix) {
+		return fmt.Errorf("alphabet must not contain the preview suffix %q", model.PreviewSuffix)
+	}
+	for i := 0; i < len(alphabet); i++ {
+		if strings.IndexByte(unreservedChars, alphabet[i]) < 0 {
+			return fmt.Errorf("alphabet character %q is not allowed in a url path, use only %s", alphabet[i], unreservedChars)
+		}
+		if strings.IndexByte(alphabet[i+1:], alphabet[i]) >= 0 {
+			return fmt.Errorf("alphabet has duplicate character %q", alphabet[i])
+		}
+	}
+	return nil
+}
+
+// Strategy стратегия генерации кода
+func (s *Shortener) Strategy() string {
+	return s.strategy
+}
+
+// GenerateUUID генерация короткого кода длины length, для counter это минимальная длина.
+// attempt — номер попытки после коллизии, стратегия hash на повторах подмешивает к url случайную соль
+func (s *Shortener) GenerateUUID(ctx context.Context, originalURL string, length int, attempt int) (string, error) {
+	if length <= 0 {
+		return "", fmt.Errorf("invalid code length %d", length)
 	}
-	return base64.URLEncoding.EncodeToString(bytes)[:lenUUID], nil
+	return s.generate(s, ctx, originalURL, length, attempt)
 }
 
 // GenerateShortURL генерация ShortURL
-func (s Shortener) GenerateShortURL(url string, uuid string) string {
+func (s *Shortener) GenerateShortURL(url string, uuid string) string {
 	return url + "/" + uuid
 }
+
+// random равномерно выбирает символы алфавита, лишние байты отбрасываются без смещения распределения
+func (s *Shortener) random(ctx context.Context, originalURL string, length int, attempt int) (string, error) {
+	n := len(s.alphabet)
+	limit := 256 - 256%n
+	code := make([]byte, 0, length)
+	buf := make([]byte, length+length/2)
+	for len(code) < length {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, b := range buf {
+			if int(b) >= limit {
+				continue
+			}
+			code = append(code, s.alphabet[int(b)%n])
+			if len(code) == length {
+				break
+			}
+		}
+	}
+	return string(code), nil
+}
+
+// hash строит код из sha256 оригинального url. Один и тот же url может сокращаться повторно,
+// поэтому после коллизии соль случайная: детерминированные повторы упирались бы в уже занятые коды
+func (s *Shortener) hash(ctx context.Context, originalURL string, length int, attempt int) (string, error) {
+	data := originalURL
+	if attempt > 0 {
+		salt := make([]byte, 8)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		data += "#" + hex.EncodeToString(salt)
+	}
+	sum := sha256.Sum256([]byte(data))
+	code := encode(new(big.Int).SetBytes(sum[:]), s.alphabet)
+	if len(code) < length {
+		return "", fmt.Errorf("code length %d exceeds hash capacity %d", length, len(code))
+	}
+	return code[:length], nil
+}
+
+// counter кодирует очередной номер последовательности по алфавиту и дополняет код слева
+// первым символом алфавита до length. Запись номера не начинается с нуля, поэтому коды не совпадают
+func (s *Shortener) counter(ctx context.Context, originalURL string, length int, attempt int) (string, error) {
+	seq, err := s.sequence.NextSequence(ctx)
+	if err != nil {
+		return "", err
+	}
+	code := encode(big.NewInt(seq), s.alphabet)
+	if len(code) < length {
+		code = strings.Repeat(s.alphabet[:1], length-len(code)) + code
+	}
+	return code, nil
+}
+
+// encode переводит число в систему счисления по алфавиту
+func encode(num *big.Int, alphabet string) string {
+	base := big.NewInt(int64(len(alphabet)))
+	if num.Sign() == 0 {
+		return alphabet[:1]
+	}
+	var (
+		digits []byte
+		mod    = new(big.Int)
+		n      = new(big.Int).Set(num)
+	)
+	for n.Sign() > 0 {
+		n.DivMod(n, base, mod)
+		digits = append(digits, alphabet[mod.Int64()])
+	}
+	for i, j := 0, len(digits)-1; i < j; i, j = i+1, j-1 {
+		digits[i], digits[j] = digits[j], digits[i]
+	}
+	return string(digits)
+}
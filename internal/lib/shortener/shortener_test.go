@@ -1 +1,111 @@
 package shortener
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSequence struct {
+	next int64
+}
+
+func (s *testSequence) NextSequence(ctx context.Context) (int64, error) {
+	s.next++
+	return s.next, nil
+}
+
+func TestShortener_Random(t *testing.T) {
+	s, err := NewShortener(StrategyRandom, "abc", nil)
+	require.NoError(t, err)
+
+	code, err := s.GenerateUUID(context.Background(), "https://google.com", 12, 0)
+	require.NoError(t, err)
+	assert.Len(t, code, 12)
+	assert.Empty(t, strings.Trim(code, "abc"), "code must use only the alphabet")
+
+	_, err = NewShortener(StrategyRandom, "ab-._~", nil)
+	assert.NoError(t, err, "unreserved characters are allowed")
+}
+
+func TestShortener_Hash(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewShortener(StrategyHash, "", nil)
+	require.NoError(t, err)
+
+	first, err := s.GenerateUUID(ctx, "https://google.com", 8, 0)
+	require.NoError(t, err)
+	again, err := s.GenerateUUID(ctx, "https://google.com", 8, 0)
+	require.NoError(t, err)
+	retry, err := s.GenerateUUID(ctx, "https://google.com", 8, 1)
+	require.NoError(t, err)
+
+	assert.Len(t, first, 8)
+	assert.Equal(t, first, again, "same url gives the same code")
+	assert.NotEqual(t, first, retry, "retry after collision gives another code")
+
+	_, err = s.GenerateUUID(ctx, "https://google.com", 100, 0)
+	assert.Error(t, err)
+}
+
+func TestShortener_Counter(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewShortener(StrategyCounter, "", &testSequence{next: 60})
+	require.NoError(t, err)
+
+	var codes []string
+	for i := 0; i < 3; i++ {
+		code, err := s.GenerateUUID(ctx, "https://google.com", 1, 0)
+		require.NoError(t, err)
+		codes = append(codes, code)
+	}
+	assert.Equal(t, []string{"z", "10", "11"}, codes)
+	assert.Equal(t, StrategyCounter, s.Strategy())
+}
+
+func TestShortener_CounterLengthAndAlphabet(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewShortener(StrategyCounter, "ab", &testSequence{next: 4})
+	require.NoError(t, err)
+
+	var codes []string
+	for i := 0; i < 3; i++ {
+		code, err := s.GenerateUUID(ctx, "https://google.com", 4, 0)
+		require.NoError(t, err)
+		codes = append(codes, code)
+	}
+	assert.Equal(t, []string{"abab", "abba", "abbb"}, codes)
+
+	code, err := s.GenerateUUID(ctx, "https://google.com", 2, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "baaa", code, "length is the minimum, longer numbers are not cut")
+}
+
+func TestNewShortener_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		alphabet string
+	}{
+		{name: "unknown strategy", strategy: "uuid"},
+		{name: "counter without sequence", strategy: StrategyCounter},
+		{name: "short alphabet", alphabet: "a"},
+		{name: "duplicate characters", alphabet: "abca"},
+		{name: "slash", alphabet: "abc/"},
+		{name: "question mark", alphabet: "abc?"},
+		{name: "preview suffix", alphabet: "abc+"},
+		{name: "percent", alphabet: "abc%"},
+		{name: "hash", alphabet: "abc#"},
+		{name: "space", alphabet: "abc "},
+		{name: "non ascii", alphabet: "abcé"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewShortener(test.strategy, test.alphabet, nil)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+// Package useragent определяет класс клиента по заголовку User-Agent.
+package useragent
+
+import "strings"
+
+// Классы клиентов
+const (
+	ClassBot     = "bot"
+	ClassMobile  = "mobile"
+	ClassTablet  = "tablet"
+	ClassDesktop = "desktop"
+	ClassOther   = "other"
+)
+
+// Платформы клиентов
+const (
+	PlatformIOS     = "ios"
+	PlatformAndroid = "android"
+	PlatformOther   = "other"
+)
+
+var botMarkers = []string{"bot", "crawler", "spider", "slurp", "curl", "wget", "python-requests", "go-http-client", "headless"}
+
+// Classify возвращает класс клиента: bot, mobile, tablet, desktop или other
+func Classify(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	switch {
+	case ua == "":
+		return ClassOther
+	case containsAny(ua, botMarkers):
+		return ClassBot
+	case containsAny(ua, []string{"ipad", "tablet"}) || strings.Contains(ua, "android") && !strings.Contains(ua, "mobile"):
+		return ClassTablet
+	case containsAny(ua, []string{"mobile", "iphone", "ipod", "android", "windows phone"}):
+		return ClassMobile
+	case containsAny(ua, []string{"windows", "macintosh", "x11", "linux", "cros"}):
+		return ClassDesktop
+	}
+	return ClassOther
+}
+
+// Platform возвращает мобильную платформу клиента: ios, android или other
+func Platform(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	switch {
+	case containsAny(ua, botMarkers):
+		return PlatformOther
+	case containsAny(ua, []string{"iphone", "ipad", "ipod"}):
+		return PlatformIOS
+	case strings.Contains(ua, "android"):
+		return PlatformAndroid
+	}
+	return PlatformOther
+}
+
+func containsAny(s string, markers []string) bool {
+	for _, marker := range markers {
+		if strings.Contains(s, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// Гранулярность агрегатов переходов
+const (
+	GranularityHour = "hour"
+	GranularityDay  = "day"
+)
+
+// Измерения, по которым считаются переходы
+const (
+	DimensionReferrer = "referrer"
+	DimensionAgent    = "agent"
+	DimensionCountry  = "country"
+)
+
+// UnknownCountry код страны, пока геолокация не подключена
+const UnknownCountry = "ZZ"
+
+// ErrInvalidGranularity кастомная ошибка "invalid granularity"
+var ErrInvalidGranularity = errors.New("invalid granularity")
+
+// ErrInvalidTimeRange кастомная ошибка "invalid time range"
+var ErrInvalidTimeRange = errors.New("invalid time range")
+
+// Click переход по короткой ссылке
+type Click struct {
+	UUID     string
+	Time     time.Time
+	Referrer string
+	Agent    string
+	Country  string
+}
+
+// ClickBucket ключ агрегата переходов за час или сутки
+type ClickBucket struct {
+	UUID        string
+	Granularity string
+	Start       time.Time
+}
+
+// ClickDimension ключ счётчика переходов по значению измерения
+type ClickDimension struct {
+	UUID      string
+	Dimension string
+	Value     string
+}
+
+// ClickRollup агрегаты пачки переходов
+type ClickRollup struct {
+	Buckets    map[ClickBucket]int64
+	Dimensions map[ClickDimension]int64
+}
+
+// RollupClicks сворачивает пачку переходов в часовые, суточные агрегаты и счётчики измерений
+func RollupClicks(clicks []Click) ClickRollup {
+	rollup := ClickRollup{
+		Buckets:    make(map[ClickBucket]int64),
+		Dimensions: make(map[ClickDimension]int64),
+	}
+	for _, click := range clicks {
+		for _, granularity := range []string{GranularityHour, GranularityDay} {
+			rollup.Buckets[ClickBucket{click.UUID, granularity, TruncateTime(click.Time, granularity)}]++
+		}
+		rollup.Dimensions[ClickDimension{click.UUID, DimensionReferrer, click.Referrer}]++
+		rollup.Dimensions[ClickDimension{click.UUID, DimensionAgent, click.Agent}]++
+		rollup.Dimensions[ClickDimension{click.UUID, DimensionCountry, click.Country}]++
+	}
+	return rollup
+}
+
+// TruncateTime начало часа или суток (UTC), в которые попадает t
+func TruncateTime(t time.Time, granularity string) time.Time {
+	t = t.UTC()
+	if granularity == GranularityDay {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return t.Truncate(time.Hour)
+}
+
+// GranularityStep длительность одного агрегата
+func GranularityStep(granularity string) time.Duration {
+	if granularity == GranularityDay {
+		return 24 * time.Hour
+	}
+	return time.Hour
+}
+
+// ClickPoint точка временного ряда переходов
+type ClickPoint struct {
+	Time   time.Time `json:"time"`
+	Clicks int64     `json:"clicks"`
+}
+
+// ClickStatsRequest запрос статистики переходов. Нулевые From и To означают значения по умолчанию
+type ClickStatsRequest struct {
+	UserID      string
+	ShortCode   string
+	Granularity string
+	From        time.Time
+	To          time.Time
+}
+
+// ClickStats статистика переходов по ссылке
+type ClickStats struct {
+	ShortCode   string           `json:"short_code"`
+	Total       int64            `json:"total"`
+	Granularity string           `json:"granularity"`
+	From        time.Time        `json:"from"`
+	To          time.Time        `json:"to"`
+	Series      []ClickPoint     `json:"series"`
+	Referrers   map[string]int64 `json:"referrers"`
+	Agents      map[string]int64 `json:"agents"`
+	Countries   map[string]int64 `json:"countries"`
+}
@@ -1,11 +1,49 @@
 package model
 
+import "context"
+
 // Event структура записи аудита
 type Event struct {
 	TimeStamp   int64  `json:"ts"`
 	Action      string `json:"action"`
 	UserID      string `json:"user_id"`
 	OriginalURL string `json:"url"`
+	Variant     string `json:"variant,omitempty"`
+}
+
+// Действия аудита
+const (
+	ActionShorten          = "shorten"
+	ActionFollow           = "follow"
+	ActionExpired          = "expired"
+	ActionClickLimit       = "click_limit"
+	ActionPasswordRequired = "password_required"
+	ActionPasswordFailed   = "password_failed"
+	ActionBlocked          = "blocked"
+	ActionEdit             = "edit"
+	ActionPreview          = "preview"
+)
+
+// Audit сведения для записи аудита, которые обработчик заполняет по ходу запроса.
+// Пустое действие middleware аудита определяет по методу запроса
+type Audit struct {
+	Action      string
+	OriginalURL string
+	Variant     string
+}
+
+// NewAuditContext добавляет в контекст запроса пустые сведения аудита
+func NewAuditContext(ctx context.Context) (context.Context, *Audit) {
+	audit := &Audit{}
+	return context.WithValue(ctx, AuditKey, audit), audit
+}
+
+// AuditFromContext сведения аудита запроса. Вне middleware аудита возвращает запись, которая никуда не попадёт
+func AuditFromContext(ctx context.Context) *Audit {
+	if audit, ok := ctx.Value(AuditKey).(*Audit); ok {
+		return audit
+	}
+	return &Audit{}
 }
 
 // EventArray список Event
@@ -1,25 +1,59 @@
 package model
 
+import "time"
+
 // HTTPServerConfig структура конфига HTTPServer
 type HTTPServerConfig struct {
 	ServerAddress string `env:"SERVER_ADDRESS"`
+	TrustedSubnet string `env:"TRUSTED_SUBNET"`
+
+	ImportMaxBytes int64 `env:"IMPORT_MAX_BYTES"`
+	ImportMaxRows  int   `env:"IMPORT_MAX_ROWS"`
 }
 
 // ShortServiceConfig структура конфига ShortService
 type ShortServiceConfig struct {
-	BaseURL string `env:"BASE_URL"`
+	BaseURL         string   `env:"BASE_URL"`
+	AliasPattern    string   `env:"ALIAS_PATTERN"`
+	AliasMinLength  int      `env:"ALIAS_MIN_LENGTH"`
+	AliasMaxLength  int      `env:"ALIAS_MAX_LENGTH"`
+	ReservedAliases []string `env:"RESERVED_ALIASES" envSeparator:","`
+
+	AllowedSchemes  []string `env:"ALLOWED_SCHEMES" envSeparator:","`
+	SortQueryParams bool     `env:"SORT_QUERY_PARAMS"`
+
+	BlocklistPath           string        `env:"BLOCKLIST_PATH"`
+	BlocklistReloadInterval time.Duration `env:"BLOCKLIST_RELOAD_INTERVAL"`
+
+	CodeStrategy   string `env:"CODE_STRATEGY"`
+	CodeAlphabet   string `env:"CODE_ALPHABET"`
+	CodeLength     int    `env:"CODE_LENGTH"`
+	CodeMaxLength  int    `env:"CODE_MAX_LENGTH"`
+	CodeMaxRetries int    `env:"CODE_MAX_RETRIES"`
+
+	PasswordMaxAttempts   int           `env:"PASSWORD_MAX_ATTEMPTS"`
+	PasswordAttemptWindow time.Duration `env:"PASSWORD_ATTEMPT_WINDOW"`
+
+	DeletedRetention time.Duration `env:"DELETED_RETENTION"`
 }
 
 // RepositoryConfig структура конфига Repository
 type RepositoryConfig struct {
-	FileStoragePath string `env:"FILE_STORAGE_PATH"`
-	DatabaseDSN     string `env:"DATABASE_DSN"`
+	FileStoragePath  string        `env:"FILE_STORAGE_PATH"`
+	DatabaseDSN      string        `env:"DATABASE_DSN"`
+	FileSyncPolicy   string        `env:"FILE_STORAGE_SYNC"`
+	FileSyncInterval time.Duration `env:"FILE_STORAGE_SYNC_INTERVAL"`
+	SnapshotInterval time.Duration `env:"FILE_STORAGE_SNAPSHOT_INTERVAL"`
+	JournalMaxSize   int64         `env:"FILE_STORAGE_JOURNAL_MAX_SIZE"`
 }
 
 // Concurrency структура конфига Concurrency
 type Concurrency struct {
-	WorkerPoolDelete *WorkerPoolDelete
-	WorkerPoolEvent  *WorkerPoolEvent
+	WorkerPoolDelete     *WorkerPoolDelete
+	WorkerPoolEvent      *WorkerPoolEvent
+	WorkerPoolExpiration *WorkerPoolExpiration
+	WorkerPoolClicks     *WorkerPoolClicks
+	WorkerPoolPurge      *WorkerPoolPurge
 }
 
 // WorkerPoolDelete структура конфига WorkerPoolDelete
@@ -36,6 +70,25 @@ type WorkerPoolEvent struct {
 	EventChainSize int
 }
 
+// WorkerPoolExpiration структура конфига WorkerPoolExpiration
+type WorkerPoolExpiration struct {
+	Interval  time.Duration
+	BatchSize int
+}
+
+// WorkerPoolPurge структура конфига WorkerPoolPurge
+type WorkerPoolPurge struct {
+	Interval  time.Duration
+	BatchSize int
+}
+
+// WorkerPoolClicks структура конфига WorkerPoolClicks
+type WorkerPoolClicks struct {
+	BufferSize    int
+	BatchSize     int
+	FlushInterval time.Duration
+}
+
 // AuditConfig структура конфига Audit
 type AuditConfig struct {
 	AuditFile string `env:"AUDIT_FILE"`
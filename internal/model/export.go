@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// URLExport ссылка пользователя в выгрузке. Clicks всего переходов по аналитике
+type URLExport struct {
+	UUID        string     `json:"uuid"`
+	ShortURL    string     `json:"short_url"`
+	OriginalURL string     `json:"original_url"`
+	CreatedAt   time.Time  `json:"created_at"`
+	DeletedFlag bool       `json:"is_deleted"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	ExpiredFlag bool       `json:"is_expired,omitempty"`
+	MaxClicks   int        `json:"max_clicks,omitempty"`
+	Clicks      int64      `json:"clicks"`
+	Tags        []string   `json:"tags,omitempty"`
+	Title       string     `json:"title,omitempty"`
+	Note        string     `json:"note,omitempty"`
+}
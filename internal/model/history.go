@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// URLHistoryEntry прежний адрес ссылки и момент его замены
+type URLHistoryEntry struct {
+	OriginalURL string    `json:"original_url"`
+	ChangedAt   time.Time `json:"changed_at"`
+}
+
+// URLEdit изменение ссылки её владельцем, применяется целиком или не применяется вовсе.
+// Пустой OriginalURL и nil Meta не меняются
+type URLEdit struct {
+	UUID        string
+	UserID      string
+	OriginalURL string
+	ChangedAt   time.Time
+	Meta        *URLMetaEdit
+}
+
+// RequestEditURL структура запроса на изменение ссылки. Отсутствующие поля не меняются
+type RequestEditURL struct {
+	OriginalURL string    `json:"original_url,omitempty"`
+	Tags        *[]string `json:"tags,omitempty"`
+	Title       *string   `json:"title,omitempty"`
+	Note        *string   `json:"note,omitempty"`
+	Preview     *bool     `json:"preview,omitempty"`
+	Passthrough *bool     `json:"passthrough,omitempty"`
+	UTM         *UTM      `json:"utm,omitempty"`
+}
+
+// URLMetaEdit изменение меток, заголовка, заметки и параметров перехода ссылки её владельцем. nil поля не меняются,
+// пустые UTM метки удаляются
+type URLMetaEdit struct {
+	Tags            *[]string
+	Title           *string
+	Note            *string
+	PreviewRequired *bool
+	Passthrough     *bool
+	UTM             *UTM
+}
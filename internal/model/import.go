@@ -0,0 +1,59 @@
+package model
+
+// ImportRow строка загрузки ссылок, Line номер строки в исходном файле
+type ImportRow struct {
+	Line        int      `json:"-"`
+	OriginalURL string   `json:"original_url"`
+	Alias       string   `json:"alias,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	ExpiresAt   string   `json:"expires_at,omitempty"`
+}
+
+// ImportLimits ограничения одной загрузки: размер тела в байтах и число строк, 0 снимает ограничение
+type ImportLimits struct {
+	MaxBytes int64
+	MaxRows  int
+}
+
+// Статусы строки отчёта импорта
+const (
+	ImportStatusCreated = "created"
+	ImportStatusExists  = "exists"
+	ImportStatusError   = "error"
+)
+
+// ImportResult результат импорта одной строки. Err причина ошибки для обработчика, клиент видит Error
+type ImportResult struct {
+	Line        int    `json:"line"`
+	OriginalURL string `json:"original_url,omitempty"`
+	Status      string `json:"status"`
+	Code        string `json:"code,omitempty"`
+	ShortURL    string `json:"short_url,omitempty"`
+	Error       string `json:"error,omitempty"`
+	Err         error  `json:"-"`
+}
+
+// ImportReport отчёт импорта по всем строкам загрузки. Error причина, по которой чтение загрузки
+// прервалось, строки до неё уже обработаны
+type ImportReport struct {
+	Total   int            `json:"total"`
+	Created int            `json:"created"`
+	Exists  int            `json:"exists"`
+	Failed  int            `json:"failed"`
+	Rows    []ImportResult `json:"rows"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// Add добавляет строку в отчёт и обновляет счётчики
+func (r *ImportReport) Add(result ImportResult) {
+	r.Total++
+	switch result.Status {
+	case ImportStatusCreated:
+		r.Created++
+	case ImportStatusExists:
+		r.Exists++
+	default:
+		r.Failed++
+	}
+	r.Rows = append(r.Rows, result)
+}
@@ -2,29 +2,83 @@ package model
 
 import (
 	"errors"
+	"time"
 )
 
 // URL
 type URL struct {
-	UUID        string `json:"uuid"`
-	ShortURL    string `json:"short_url"`
-	OriginalURL string `json:"original_url"`
-	UserID      string `json:"user_id"`
-	DeletedFlag bool   `json:"is_deleted"`
+	UUID         string     `json:"uuid"`
+	ShortURL     string     `json:"short_url"`
+	OriginalURL  string     `json:"original_url"`
+	UserID       string     `json:"user_id"`
+	DeletedFlag  bool       `json:"is_deleted"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+	ForceNew     bool       `json:"force_new,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	ExpiredFlag  bool       `json:"is_expired,omitempty"`
+	MaxClicks    int        `json:"max_clicks,omitempty"`
+	Clicks       int        `json:"clicks,omitempty"`
+	PasswordHash string     `json:"password_hash,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	Tags         []string   `json:"tags,omitempty"`
+	Title        string     `json:"title,omitempty"`
+	Note         string     `json:"note,omitempty"`
+	// PreviewRequired переход по ссылке сначала показывает страницу предпросмотра
+	PreviewRequired bool `json:"preview_required,omitempty"`
+	// Targets варианты адреса для A/B распределения переходов, OriginalURL совпадает с первым из них
+	Targets []Target `json:"targets,omitempty"`
+	// Rules правила перенаправления, проверяются по порядку до выбора варианта
+	Rules []Rule `json:"rules,omitempty"`
+	// Passthrough параметры запроса короткой ссылки переносятся в адрес перехода
+	Passthrough bool `json:"passthrough,omitempty"`
+	// UTM метки, которые добавляются к адресу перехода
+	UTM *UTM `json:"utm,omitempty"`
+	// History прежние адреса ссылки, хранится только в файловом хранилище
+	History []URLHistoryEntry `json:"history,omitempty"`
+}
+
+// IsProtected проверяет, защищена ли ссылка паролем
+func (u *URL) IsProtected() bool {
+	return u.PasswordHash != ""
+}
+
+// IsExpired проверяет, истёк ли срок жизни ссылки на момент now
+func (u *URL) IsExpired(now time.Time) bool {
+	return u.ExpiredFlag || u.ExpiresAt != nil && !now.Before(*u.ExpiresAt)
 }
 
 // URLArray список URL
 type URLArray []URL
 
+// ShortenOptions параметры создания короткой ссылки
+type ShortenOptions struct {
+	ForceNew  bool       `json:"force_new,omitempty"`
+	Alias     string     `json:"alias,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	TTL       string     `json:"ttl,omitempty"`
+	MaxClicks int        `json:"max_clicks,omitempty"`
+	Password  string     `json:"password,omitempty"`
+	Tags      []string   `json:"tags,omitempty"`
+	Title     string     `json:"title,omitempty"`
+	Note      string     `json:"note,omitempty"`
+	Preview   bool       `json:"preview,omitempty"`
+	Targets   []Target   `json:"targets,omitempty"`
+	// Passthrough переносить параметры запроса короткой ссылки в адрес перехода
+	Passthrough bool `json:"passthrough,omitempty"`
+	UTM         *UTM `json:"utm,omitempty"`
+}
+
 // RequestShortener
 type RequestShortener struct {
 	URL    string `json:"url"`
-	UserID string
+	UserID string `json:"-"`
+	ShortenOptions
 }
 
-// ResponseShortener структура для ответа в json
+// ResponseShortener структура для ответа в json. QR код короткой ссылки в виде data URI, если он запрошен
 type ResponseShortener struct {
 	Result string `json:"result"`
+	QR     string `json:"qr,omitempty"`
 }
 
 // RequestShortenerBatchArray список RequestShortenerBatch
@@ -34,6 +88,7 @@ type RequestShortenerBatchArray []RequestShortenerBatch
 type RequestShortenerBatch struct {
 	CorrelationID string `json:"correlation_id"`
 	OriginalURL   string `json:"original_url"`
+	ShortenOptions
 }
 
 // ResponseShortenerBatchArray список ResponseShortenerBatch
@@ -48,10 +103,100 @@ type ResponseShortenerBatch struct {
 // ErrURLConflict кастомная ошибка "URL already exists"
 var ErrURLConflict = errors.New("URL already exists")
 
+// ErrShortCodeConflict кастомная ошибка "short code already exists"
+var ErrShortCodeConflict = errors.New("short code already exists")
+
+// ErrShortCodeExhausted кастомная ошибка "no free short code"
+var ErrShortCodeExhausted = errors.New("no free short code")
+
+// ErrInvalidURL кастомная ошибка "invalid url"
+var ErrInvalidURL = errors.New("invalid url")
+
+// ErrURLNotFound кастомная ошибка "url not found"
+var ErrURLNotFound = errors.New("url not found")
+
+// ErrURLBlocked кастомная ошибка "url is blocked"
+var ErrURLBlocked = errors.New("url is blocked")
+
+// ErrInvalidAlias кастомная ошибка "invalid alias"
+var ErrInvalidAlias = errors.New("invalid alias")
+
+// ErrInvalidExpiry кастомная ошибка "invalid expiry"
+var ErrInvalidExpiry = errors.New("invalid expiry")
+
+// ErrInvalidMaxClicks кастомная ошибка "invalid max_clicks"
+var ErrInvalidMaxClicks = errors.New("invalid max_clicks")
+
+// ErrInvalidPassword кастомная ошибка "invalid password"
+var ErrInvalidPassword = errors.New("invalid password")
+
+// ErrWrongPassword кастомная ошибка "wrong password"
+var ErrWrongPassword = errors.New("wrong password")
+
+// ErrInvalidMetadata кастомная ошибка "invalid metadata"
+var ErrInvalidMetadata = errors.New("invalid metadata")
+
+// ErrInvalidQROptions кастомная ошибка "invalid qr options"
+var ErrInvalidQROptions = errors.New("invalid qr options")
+
+// ErrTooManyAttempts кастомная ошибка "too many attempts"
+var ErrTooManyAttempts = errors.New("too many attempts")
+
+// PasswordHeader заголовок, в котором JSON клиенты передают пароль ссылки
+const PasswordHeader = "X-Link-Password"
+
+// RequestError ошибка в данных запроса с причиной, которую можно показать клиенту
+type RequestError struct {
+	Err    error
+	Reason string
+}
+
+// NewRequestError конструктор RequestError
+func NewRequestError(err error, reason string) *RequestError {
+	return &RequestError{Err: err, Reason: reason}
+}
+
+// Error текст ошибки вместе с причиной
+func (e *RequestError) Error() string {
+	return e.Err.Error() + ": " + e.Reason
+}
+
+// Unwrap исходная ошибка для errors.Is
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// BatchItemError ошибка сохранения одной ссылки из пачки, OriginalURL адрес из запроса
+type BatchItemError struct {
+	CorrelationID string
+	OriginalURL   string
+	Err           error
+}
+
+// Error текст исходной ошибки
+func (e *BatchItemError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap исходная ошибка для errors.Is и errors.As
+func (e *BatchItemError) Unwrap() error {
+	return e.Err
+}
+
 // URLUser структура для ответа в json
 type URLUser struct {
-	ShortURL    string `json:"short_url"`
-	OriginalURL string `json:"original_url"`
+	UUID        string    `json:"uuid"`
+	ShortURL    string    `json:"short_url"`
+	OriginalURL string    `json:"original_url"`
+	CreatedAt   time.Time `json:"created_at"`
+	DeletedFlag bool      `json:"is_deleted"`
+	Tags        []string  `json:"tags,omitempty"`
+	Title       string    `json:"title,omitempty"`
+	Note        string    `json:"note,omitempty"`
+	// PreviewRequired переход по ссылке сначала показывает страницу предпросмотра
+	PreviewRequired bool `json:"preview_required,omitempty"`
+	Passthrough     bool `json:"passthrough,omitempty"`
+	UTM             *UTM `json:"utm,omitempty"`
 }
 
 // URLUserBatch список URLUser
@@ -63,19 +208,37 @@ type contextKey string
 const (
 	UserIDKey   contextKey = "userID"
 	OriginalURL contextKey = "originalURL"
+	AuditKey    contextKey = "audit"
 )
 
 // URLUserRequest структура для запроса url по userid
 type URLUserRequest struct {
-	UUID   string
-	UserID string
+	UUID    string
+	UserID  string
+	Restore bool
 }
 
 // URLUserRequestArray список URLUserRequest
 type URLUserRequestArray []URLUserRequest
 
-// DeleteRequest структура запроса на удаление
+// DeleteRequest структура запроса на удаление. С Restore запрос восстанавливает удалённые ссылки
 type DeleteRequest struct {
-	UUIDs  []string `json:"uuids"`
-	UserID string   `json:"user_id"`
+	UUIDs   []string `json:"uuids"`
+	UserID  string   `json:"user_id"`
+	Restore bool     `json:"restore,omitempty"`
+}
+
+// RepositoryStats состояние хранилища
+type RepositoryStats struct {
+	URLs         int         `json:"urls"`
+	JournalSize  int64       `json:"journal_size,omitempty"`
+	LastSnapshot *time.Time  `json:"last_snapshot,omitempty"`
+	Purge        *PurgeStats `json:"purge,omitempty"`
+}
+
+// PurgeStats результаты очистки удалённых ссылок
+type PurgeStats struct {
+	LastRun     *time.Time `json:"last_run,omitempty"`
+	LastPurged  int        `json:"last_purged"`
+	TotalPurged int64      `json:"total_purged"`
 }
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// PreviewSuffix окончание короткого кода, запрашивающее предпросмотр вместо перехода.
+// Сгенерированные коды и alias не должны его содержать
+const PreviewSuffix = "+"
+
+// Состояния ссылки на странице предпросмотра
+const (
+	PreviewStatusSafe       = "safe"
+	PreviewStatusBlocked    = "blocked"
+	PreviewStatusExpired    = "expired"
+	PreviewStatusClickLimit = "click_limit"
+)
+
+// LinkPreview сведения о ссылке для страницы предпросмотра.
+// Адрес защищённой паролем ссылки не раскрывается
+type LinkPreview struct {
+	ShortURL        string    `json:"short_url"`
+	OriginalURL     string    `json:"original_url,omitempty"`
+	Title           string    `json:"title,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	Status          string    `json:"status"`
+	Reason          string    `json:"reason,omitempty"`
+	Protected       bool      `json:"protected,omitempty"`
+	PreviewRequired bool      `json:"preview_required,omitempty"`
+	ContinueURL     string    `json:"continue_url,omitempty"`
+}
@@ -0,0 +1,43 @@
+package model
+
+import "errors"
+
+// UTM метки кампании, которые добавляются к адресу при переходе. Пустые метки не добавляются
+type UTM struct {
+	Source   string `json:"source,omitempty"`
+	Medium   string `json:"medium,omitempty"`
+	Campaign string `json:"campaign,omitempty"`
+	Term     string `json:"term,omitempty"`
+	Content  string `json:"content,omitempty"`
+}
+
+// IsZero проверяет, что ни одна метка не задана
+func (u UTM) IsZero() bool {
+	return u == UTM{}
+}
+
+// Params метки в виде параметров запроса utm_* в порядке source, medium, campaign, term, content
+func (u UTM) Params() [][2]string {
+	var params [][2]string
+	for _, param := range [][2]string{
+		{"utm_source", u.Source},
+		{"utm_medium", u.Medium},
+		{"utm_campaign", u.Campaign},
+		{"utm_term", u.Term},
+		{"utm_content", u.Content},
+	} {
+		if param[1] != "" {
+			params = append(params, param)
+		}
+	}
+	return params
+}
+
+// Шаблоны, которые подставляются в адрес перехода и в UTM метки
+const (
+	PlaceholderCode = "{code}"
+	PlaceholderTS   = "{ts}"
+)
+
+// ErrInvalidRedirect кастомная ошибка "invalid redirect options"
+var ErrInvalidRedirect = errors.New("invalid redirect options")
@@ -0,0 +1,28 @@
+package model
+
+import "errors"
+
+// Rule правило перенаправления ссылки. Переход уходит на URL, если выполнены все заданные условия:
+// класс или платформа устройства, предпочитаемый язык, параметры запроса и время суток с From до To
+type Rule struct {
+	URL      string            `json:"url"`
+	Device   string            `json:"device,omitempty"`
+	Language string            `json:"language,omitempty"`
+	Query    map[string]string `json:"query,omitempty"`
+	From     string            `json:"from,omitempty"`
+	To       string            `json:"to,omitempty"`
+	Timezone string            `json:"timezone,omitempty"`
+}
+
+// RuleTimeLayout формат времени суток в правилах
+const RuleTimeLayout = "15:04"
+
+// URLRulesEdit замена правил перенаправления ссылки её владельцем, пустой список удаляет правила
+type URLRulesEdit struct {
+	UUID   string
+	UserID string
+	Rules  []Rule
+}
+
+// ErrInvalidRules кастомная ошибка "invalid rules"
+var ErrInvalidRules = errors.New("invalid rules")
@@ -0,0 +1,51 @@
+package model
+
+import "errors"
+
+// Target вариант адреса назначения ссылки с долей переходов weight
+type Target struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Weight int    `json:"weight"`
+}
+
+// ErrInvalidTargets кастомная ошибка "invalid targets"
+var ErrInvalidTargets = errors.New("invalid targets")
+
+// VariantCookie cookie, закрепляющая за посетителем вариант ссылки
+const VariantCookie = "Variant"
+
+// IsSplit проверяет, распределяются ли переходы по ссылке между несколькими адресами
+func (u *URL) IsSplit() bool {
+	return len(u.Targets) > 0
+}
+
+// Variant ищет вариант ссылки по имени
+func (u *URL) Variant(name string) (Target, bool) {
+	for _, target := range u.Targets {
+		if target.Name == name {
+			return target, true
+		}
+	}
+	return Target{}, false
+}
+
+// TotalWeight сумма весов вариантов ссылки
+func (u *URL) TotalWeight() int {
+	total := 0
+	for _, target := range u.Targets {
+		total += target.Weight
+	}
+	return total
+}
+
+// PickVariant выбирает вариант, на долю которого приходится n из [0, TotalWeight)
+func (u *URL) PickVariant(n int) Target {
+	for _, target := range u.Targets {
+		if n < target.Weight {
+			return target
+		}
+		n -= target.Weight
+	}
+	return u.Targets[len(u.Targets)-1]
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Статусы ссылок в фильтре списка ссылок пользователя
+const (
+	URLStatusAll     = "all"
+	URLStatusActive  = "active"
+	URLStatusDeleted = "deleted"
+)
+
+// Порядок сортировки списка ссылок пользователя по времени создания
+const (
+	SortCreatedDesc = "created_desc"
+	SortCreatedAsc  = "created_asc"
+)
+
+// ErrInvalidFilter кастомная ошибка "invalid filter"
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// ErrInvalidCursor кастомная ошибка "invalid cursor"
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// NextCursorHeader заголовок ответа со списком ссылок пользователя, курсор следующей страницы
+const NextCursorHeader = "X-Next-Cursor"
+
+// URLUserFilter параметры выборки ссылок пользователя. Limit <= 0 означает выборку без ограничения.
+// Query ищет подстроку в адресе, заголовке и заметке, ссылка должна иметь все метки из Tags
+type URLUserFilter struct {
+	UserID       string
+	Limit        int
+	Cursor       *URLUserCursor
+	Status       string
+	CreatedAfter *time.Time
+	Query        string
+	Tags         []string
+	Sort         string
+}
+
+// URLUserCursor позиция последней отданной ссылки, выборка продолжается строго после неё
+type URLUserCursor struct {
+	CreatedAt time.Time
+	UUID      string
+}
+
+// String непрозрачное представление курсора для клиента
+func (c URLUserCursor) String() string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixMicro(), 10) + ":" + c.UUID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseURLUserCursor разбирает курсор, полученный от клиента
+func ParseURLUserCursor(s string) (*URLUserCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, NewRequestError(ErrInvalidCursor, "cursor is malformed")
+	}
+	micros, uuid, ok := strings.Cut(string(raw), ":")
+	if !ok || uuid == "" {
+		return nil, NewRequestError(ErrInvalidCursor, "cursor is malformed")
+	}
+	usec, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return nil, NewRequestError(ErrInvalidCursor, "cursor is malformed")
+	}
+	return &URLUserCursor{CreatedAt: time.UnixMicro(usec).UTC(), UUID: uuid}, nil
+}
+
+// URLUserPage страница списка ссылок пользователя. Клиент получает только URLs,
+// курсор следующей страницы передаётся в заголовках ответа
+type URLUserPage struct {
+	URLs       URLUserBatch
+	NextCursor string
+}
@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// clickStore агрегаты переходов. Хранятся только в памяти и не попадают в снимок и журнал
+type clickStore struct {
+	mu         sync.RWMutex
+	buckets    map[model.ClickBucket]int64
+	totals     map[string]int64
+	dimensions map[string]map[model.ClickDimension]int64
+}
+
+func newClickStore() *clickStore {
+	return &clickStore{
+		buckets:    make(map[model.ClickBucket]int64),
+		totals:     make(map[string]int64),
+		dimensions: make(map[string]map[model.ClickDimension]int64),
+	}
+}
+
+// SaveClicks метод сохранения пачки переходов в агрегаты
+func (r *MemoryRepository) SaveClicks(ctx context.Context, clicks []model.Click) error {
+	rollup := model.RollupClicks(clicks)
+
+	s := r.clicks
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, count := range rollup.Buckets {
+		s.buckets[key] += count
+		if key.Granularity == model.GranularityDay {
+			s.totals[key.UUID] += count
+		}
+	}
+	for key, count := range rollup.Dimensions {
+		dims, ok := s.dimensions[key.UUID]
+		if !ok {
+			dims = make(map[model.ClickDimension]int64)
+			s.dimensions[key.UUID] = dims
+		}
+		dims[key] += count
+	}
+	return nil
+}
+
+// ClickStats метод получения статистики переходов за [from, to), ряд содержит только непустые агрегаты
+func (r *MemoryRepository) ClickStats(ctx context.Context, uuid string, granularity string, from, to time.Time) (*model.ClickStats, error) {
+	s := r.clicks
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats := &model.ClickStats{
+		ShortCode:   uuid,
+		Total:       s.totals[uuid],
+		Granularity: granularity,
+		Referrers:   make(map[string]int64),
+		Agents:      make(map[string]int64),
+		Countries:   make(map[string]int64),
+	}
+	step := model.GranularityStep(granularity)
+	for start := model.TruncateTime(from, granularity); start.Before(to); start = start.Add(step) {
+		if count, ok := s.buckets[model.ClickBucket{UUID: uuid, Granularity: granularity, Start: start}]; ok {
+			stats.Series = append(stats.Series, model.ClickPoint{Time: start, Clicks: count})
+		}
+	}
+	for key, count := range s.dimensions[uuid] {
+		switch key.Dimension {
+		case model.DimensionReferrer:
+			stats.Referrers[key.Value] = count
+		case model.DimensionAgent:
+			stats.Agents[key.Value] = count
+		case model.DimensionCountry:
+			stats.Countries[key.Value] = count
+		}
+	}
+	return stats, nil
+}
+
+// remove удаляет агрегаты переходов ссылок uuids
+func (s *clickStore) remove(uuids map[string]struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.buckets {
+		if _, ok := uuids[key.UUID]; ok {
+			delete(s.buckets, key)
+		}
+	}
+	for uuid := range uuids {
+		delete(s.totals, uuid)
+		delete(s.dimensions, uuid)
+	}
+}
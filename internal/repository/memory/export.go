@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// Export метод выгрузки всех ссылок пользователя по порядку создания.
+// Блокировка берётся на каждую ссылку, чтобы медленный клиент не держал хранилище
+func (r *MemoryRepository) Export(ctx context.Context, userID string, yield func(model.URLExport) error) error {
+	r.mu.RLock()
+	uuids := slices.Clone(r.userURLs[userID])
+	slices.SortFunc(uuids, func(a, b string) int {
+		return compareUserURL(r.listURLs[a].CreatedAt, a, r.listURLs[b].CreatedAt, b)
+	})
+	r.mu.RUnlock()
+
+	for _, uuid := range uuids {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		r.mu.RLock()
+		url, ok := r.listURLs[uuid]
+		var export model.URLExport
+		if ok {
+			export = model.URLExport{
+				UUID:        url.UUID,
+				ShortURL:    url.ShortURL,
+				OriginalURL: url.OriginalURL,
+				CreatedAt:   url.CreatedAt,
+				DeletedFlag: url.DeletedFlag,
+				DeletedAt:   url.DeletedAt,
+				ExpiresAt:   url.ExpiresAt,
+				ExpiredFlag: url.ExpiredFlag,
+				MaxClicks:   url.MaxClicks,
+				Tags:        url.Tags,
+				Title:       url.Title,
+				Note:        url.Note,
+			}
+		}
+		r.mu.RUnlock()
+		if !ok {
+			continue
+		}
+
+		r.clicks.mu.RLock()
+		export.Clicks = r.clicks.totals[uuid]
+		r.clicks.mu.RUnlock()
+
+		if err := yield(export); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// UpdateURL метод изменения ссылки владельцем, прежний адрес попадает в историю.
+// Все изменения пишутся одной записью журнала. История хранится в самой записи url,
+// поэтому переживает снимок и журнал
+func (r *MemoryRepository) UpdateURL(ctx context.Context, edit model.URLEdit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	url, ok := r.listURLs[edit.UUID]
+	if !ok || url.UserID != edit.UserID || url.DeletedFlag {
+		return model.ErrURLNotFound
+	}
+	moved := edit.OriginalURL != "" && edit.OriginalURL != url.OriginalURL
+	if !moved && edit.Meta == nil {
+		return nil
+	}
+	edited := *url
+	if moved {
+		newKey := ownerKey{url.UserID, edit.OriginalURL}
+		if uuid, ok := r.ownerURLs[newKey]; ok && !url.ForceNew && !url.ExpiredFlag && uuid != url.UUID {
+			return model.ErrURLConflict
+		}
+		edited.OriginalURL = edit.OriginalURL
+		edited.History = append(slices.Clone(url.History), model.URLHistoryEntry{
+			OriginalURL: url.OriginalURL,
+			ChangedAt:   edit.ChangedAt,
+		})
+	}
+	if edit.Meta != nil {
+		applyMeta(&edited, *edit.Meta)
+	}
+	if err := r.appendJournal(journalRecord{Op: opPut, URL: &edited}); err != nil {
+		return err
+	}
+	if oldKey := (ownerKey{url.UserID, url.OriginalURL}); moved && r.ownerURLs[oldKey] == url.UUID {
+		delete(r.ownerURLs, oldKey)
+	}
+	r.put(&edited)
+	return nil
+}
+
+// History метод получения прежних адресов ссылки в порядке изменения
+func (r *MemoryRepository) History(ctx context.Context, uuid string) ([]model.URLHistoryEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	url, ok := r.listURLs[uuid]
+	if !ok {
+		return nil, model.ErrURLNotFound
+	}
+	history := make([]model.URLHistoryEntry, len(url.History))
+	copy(history, url.History)
+	return history, nil
+}
+
+// applyMeta применяет к копии ссылки изменение меток, заголовка, заметки и параметров перехода
+func applyMeta(edited *model.URL, edit model.URLMetaEdit) {
+	if edit.Tags != nil {
+		edited.Tags = slices.Clone(*edit.Tags)
+	}
+	if edit.Title != nil {
+		edited.Title = *edit.Title
+	}
+	if edit.Note != nil {
+		edited.Note = *edit.Note
+	}
+	if edit.PreviewRequired != nil {
+		edited.PreviewRequired = *edit.PreviewRequired
+	}
+	if edit.Passthrough != nil {
+		edited.Passthrough = *edit.Passthrough
+	}
+	if edit.UTM != nil {
+		edited.UTM = nil
+		if !edit.UTM.IsZero() {
+			utm := *edit.UTM
+			edited.UTM = &utm
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// gramSize длина n-граммы поискового индекса. Запросы короче ищутся перебором ссылок пользователя
+const gramSize = 3
+
+// tagKey ключ индекса меток: метки ищутся только среди ссылок пользователя
+type tagKey struct {
+	userID string
+	tag    string
+}
+
+// searchIndex инвертированные индексы меток и триграмм адреса, заголовка и заметки
+type searchIndex struct {
+	tags  map[tagKey]map[string]struct{}
+	grams map[string]map[string]struct{}
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		tags:  make(map[tagKey]map[string]struct{}),
+		grams: make(map[string]map[string]struct{}),
+	}
+}
+
+// searchText текст ссылки, по которому ищет подстрока фильтра
+func searchText(url *model.URL) string {
+	return strings.ToLower(url.OriginalURL + " " + url.Title + " " + url.Note)
+}
+
+// trigrams уникальные n-граммы строки по рунам
+func trigrams(s string) []string {
+	if utf8.RuneCountInString(s) < gramSize {
+		return nil
+	}
+	runes := []rune(s)
+	grams := make([]string, 0, len(runes)-gramSize+1)
+	for i := 0; i+gramSize <= len(runes); i++ {
+		grams = append(grams, string(runes[i:i+gramSize]))
+	}
+	slices.Sort(grams)
+	return slices.Compact(grams)
+}
+
+// update переиндексирует ссылку, old или url могут быть nil. Вызывается под блокировкой хранилища
+func (ix *searchIndex) update(old, url *model.URL) {
+	if old != nil && url != nil && old.UserID == url.UserID &&
+		slices.Equal(old.Tags, url.Tags) && searchText(old) == searchText(url) {
+		return
+	}
+	if old != nil {
+		ix.apply(old, func(set map[string]struct{}) { delete(set, old.UUID) })
+	}
+	if url != nil {
+		ix.apply(url, func(set map[string]struct{}) { set[url.UUID] = struct{}{} })
+	}
+}
+
+// apply вызывает fn для всех списков индекса, в которые входит url, и удаляет опустевшие
+func (ix *searchIndex) apply(url *model.URL, fn func(set map[string]struct{})) {
+	for _, tag := range url.Tags {
+		key := tagKey{url.UserID, tag}
+		set, ok := ix.tags[key]
+		if !ok {
+			set = make(map[string]struct{})
+			ix.tags[key] = set
+		}
+		if fn(set); len(set) == 0 {
+			delete(ix.tags, key)
+		}
+	}
+	for _, gram := range trigrams(searchText(url)) {
+		set, ok := ix.grams[gram]
+		if !ok {
+			set = make(map[string]struct{})
+			ix.grams[gram] = set
+		}
+		if fn(set); len(set) == 0 {
+			delete(ix.grams, gram)
+		}
+	}
+}
+
+// candidates uuid ссылок, которые могут подойти под метки и строку поиска фильтра.
+// ok == false, если фильтр не сужает выборку через индекс
+func (ix *searchIndex) candidates(filter model.URLUserFilter) (map[string]struct{}, bool) {
+	var postings []map[string]struct{}
+	for _, tag := range filter.Tags {
+		postings = append(postings, ix.tags[tagKey{filter.UserID, tag}])
+	}
+	for _, gram := range trigrams(strings.ToLower(filter.Query)) {
+		postings = append(postings, ix.grams[gram])
+	}
+	if len(postings) == 0 {
+		return nil, false
+	}
+	slices.SortFunc(postings, func(a, b map[string]struct{}) int { return len(a) - len(b) })
+
+	result := make(map[string]struct{}, len(postings[0]))
+	for uuid := range postings[0] {
+		found := true
+		for _, set := range postings[1:] {
+			if _, found = set[uuid]; !found {
+				break
+			}
+		}
+		if found {
+			result[uuid] = struct{}{}
+		}
+	}
+	return result, true
+}
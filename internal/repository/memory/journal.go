@@ -0,0 +1,233 @@
+package memory
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// Политики синхронизации журнала с диском
+const (
+	SyncAlways   = "always"
+	SyncInterval = "interval"
+	SyncNone     = "none"
+)
+
+const (
+	journalSuffix       = ".journal"
+	defaultSyncInterval = time.Second
+)
+
+// операции журнала
+const (
+	opPut      = "put"
+	opSequence = "sequence"
+	opRemove   = "remove"
+)
+
+// journalRecord строка журнала (JSON lines)
+type journalRecord struct {
+	Op       string     `json:"op"`
+	URL      *model.URL `json:"url,omitempty"`
+	Sequence int64      `json:"sequence,omitempty"`
+	UUID     string     `json:"uuid,omitempty"`
+}
+
+// journal журнал изменений хранилища, дописывается построчно
+type journal struct {
+	mu       sync.Mutex
+	file     *os.File
+	policy   string
+	interval time.Duration
+	dirty    bool
+	size     int64
+	logger   *slog.Logger
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// openJournal открывает журнал на дозапись и запускает фоновую синхронизацию для политики interval
+func openJournal(path string, policy string, interval time.Duration, log *slog.Logger) (*journal, error) {
+	const op = "memory.openJournal"
+
+	switch policy {
+	case "":
+		policy = SyncAlways
+	case SyncAlways, SyncInterval, SyncNone:
+	default:
+		return nil, fmt.Errorf("%s: unknown sync policy %q", op, policy)
+	}
+	if interval <= 0 {
+		interval = defaultSyncInterval
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("%s: %w", op, err), file.Close())
+	}
+	j := &journal{
+		file:     file,
+		policy:   policy,
+		interval: interval,
+		size:     info.Size(),
+		logger:   log,
+		stop:     make(chan struct{}),
+	}
+	if policy == SyncInterval {
+		j.wg.Add(1)
+		go j.syncLoop()
+	}
+	return j, nil
+}
+
+func (j *journal) syncLoop() {
+	const op = "memory.journal.syncLoop"
+	defer j.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-j.stop:
+			return
+		case <-ticker.C:
+			if err := j.Sync(); err != nil {
+				j.logger.Error(op, "error", err)
+			}
+		}
+	}
+}
+
+// Append дописывает записи в журнал одной операцией записи
+func (j *journal) Append(records ...journalRecord) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for i := range records {
+		if err := encoder.Encode(&records[i]); err != nil {
+			return err
+		}
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	n, err := j.file.Write(buf.Bytes())
+	j.size += int64(n)
+	if err != nil {
+		return err
+	}
+	if j.policy == SyncAlways {
+		return j.file.Sync()
+	}
+	j.dirty = true
+	return nil
+}
+
+// Sync сбрасывает журнал на диск, если были изменения
+func (j *journal) Sync() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if !j.dirty {
+		return nil
+	}
+	if err := j.file.Sync(); err != nil {
+		return err
+	}
+	j.dirty = false
+	return nil
+}
+
+// Truncate очищает журнал после записи снимка
+func (j *journal) Truncate() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.file.Truncate(0); err != nil {
+		return err
+	}
+	j.size = 0
+	j.dirty = false
+	return j.file.Sync()
+}
+
+// Size текущий размер журнала в байтах
+func (j *journal) Size() int64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.size
+}
+
+// Close останавливает синхронизацию и закрывает файл журнала
+func (j *journal) Close() error {
+	close(j.stop)
+	j.wg.Wait()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return errors.Join(j.file.Sync(), j.file.Close())
+}
+
+// readJournal читает записи журнала. Оборванная последняя строка отбрасывается,
+// а файл обрезается до последней целой записи.
+func readJournal(path string) ([]journalRecord, error) {
+	const op = "memory.readJournal"
+
+	file, err := os.OpenFile(path, os.O_RDWR, 0644)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer file.Close()
+
+	var (
+		records []journalRecord
+		offset  int64
+		torn    bool
+	)
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			torn = len(line) > 0
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		var record journalRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			if _, peekErr := reader.Peek(1); errors.Is(peekErr, io.EOF) {
+				torn = true
+				break
+			}
+			return nil, fmt.Errorf("%s: corrupted record at offset %d: %w", op, offset, err)
+		}
+		records = append(records, record)
+		offset += int64(len(line))
+	}
+
+	if torn {
+		if err := file.Truncate(offset); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	return records, nil
+}
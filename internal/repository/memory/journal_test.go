@@ -0,0 +1,156 @@
+package memory
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRepository_ReplayJournal(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &model.RepositoryConfig{
+		FileStoragePath: filepath.Join(t.TempDir(), "storage.json"),
+		FileSyncPolicy:  SyncAlways,
+	}
+
+	repo, err := NewMemoryRepository(ctx, cfg, logger)
+	require.NoError(t, err)
+	_, err = repo.Save(ctx, &model.URL{UUID: "aaa", OriginalURL: "https://google.com", UserID: "1"})
+	require.NoError(t, err)
+	_, err = repo.Save(ctx, &model.URL{UUID: "bbb", OriginalURL: "https://yandex.ru", UserID: "1"})
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteBatch(ctx, model.URLUserRequestArray{{UUID: "bbb", UserID: "1"}}))
+
+	// имитируем падение процесса: Close не вызывается, последняя строка журнала оборвана
+	file, err := os.OpenFile(cfg.FileStoragePath+journalSuffix, os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"op":"put","url":{"uuid":"ccc"`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	restored, err := NewMemoryRepository(ctx, cfg, logger)
+	require.NoError(t, err)
+
+	url, err := restored.Get(ctx, "aaa")
+	require.NoError(t, err)
+	assert.Equal(t, "https://google.com", url.OriginalURL)
+
+	url, err = restored.Get(ctx, "bbb")
+	require.NoError(t, err)
+	assert.True(t, url.DeletedFlag)
+
+	_, err = restored.Get(ctx, "ccc")
+	assert.Error(t, err)
+
+	batch, err := restored.GetBatch(ctx, model.URLUserFilter{UserID: "1"})
+	require.NoError(t, err)
+	assert.Len(t, batch, 2)
+}
+
+func TestReadJournal_CorruptedRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage.json"+journalSuffix)
+	data := `{"op":"put","url":{"uuid":"aaa"}}` + "\n" + `{broken` + "\n" + `{"op":"put","url":{"uuid":"bbb"}}` + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(data), 0644))
+
+	_, err := readJournal(path)
+	assert.Error(t, err)
+}
+
+func TestMemoryRepository_Snapshot(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &model.RepositoryConfig{
+		FileStoragePath: filepath.Join(t.TempDir(), "storage.json"),
+		FileSyncPolicy:  SyncNone,
+		JournalMaxSize:  1,
+	}
+
+	repo, err := NewMemoryRepository(ctx, cfg, logger)
+	require.NoError(t, err)
+	_, err = repo.Save(ctx, &model.URL{UUID: "aaa", OriginalURL: "https://google.com", UserID: "1"})
+	require.NoError(t, err)
+
+	// превышение размера журнала запускает снимок в фоне
+	require.Eventually(t, func() bool {
+		stats, err := repo.Stats(ctx)
+		return err == nil && stats.LastSnapshot != nil && stats.JournalSize == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	info, err := os.Stat(cfg.FileStoragePath + journalSuffix)
+	require.NoError(t, err)
+	assert.Zero(t, info.Size())
+	require.NoError(t, repo.Close())
+
+	restored, err := NewMemoryRepository(ctx, cfg, logger)
+	require.NoError(t, err)
+	url, err := restored.Get(ctx, "aaa")
+	require.NoError(t, err)
+	assert.Equal(t, "https://google.com", url.OriginalURL)
+	require.NoError(t, restored.Close())
+}
+
+func TestMemoryRepository_SequenceSurvivesSnapshot(t *testing.T) {
+	ctx := context.Background()
+	cfg := &model.RepositoryConfig{
+		FileStoragePath: filepath.Join(t.TempDir(), "storage.json"),
+		FileSyncPolicy:  SyncNone,
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	repo, err := NewMemoryRepository(ctx, cfg, logger)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = repo.NextSequence(ctx)
+		require.NoError(t, err)
+	}
+	require.NoError(t, repo.snapshot())
+	require.NoError(t, repo.Close())
+
+	repo, err = NewMemoryRepository(ctx, cfg, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = repo.Close() })
+
+	next, err := repo.NextSequence(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, next)
+}
+
+func TestMemoryRepository_RulesSurviveRestart(t *testing.T) {
+	ctx := context.Background()
+	cfg := &model.RepositoryConfig{
+		FileStoragePath: filepath.Join(t.TempDir(), "storage.json"),
+		FileSyncPolicy:  SyncNone,
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	rules := []model.Rule{{URL: "https://apps.apple.com/app/id1", Device: "ios"}}
+
+	repo, err := NewMemoryRepository(ctx, cfg, logger)
+	require.NoError(t, err)
+	_, err = repo.Save(ctx, &model.URL{UUID: "aaa", OriginalURL: "https://google.com", UserID: "1"})
+	require.NoError(t, err)
+	assert.ErrorIs(t, repo.UpdateRules(ctx, model.URLRulesEdit{UUID: "aaa", UserID: "2", Rules: rules}), model.ErrURLNotFound)
+	require.NoError(t, repo.UpdateRules(ctx, model.URLRulesEdit{UUID: "aaa", UserID: "1", Rules: rules}))
+	require.NoError(t, repo.Close())
+
+	repo, err = NewMemoryRepository(ctx, cfg, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = repo.Close() })
+
+	url, err := repo.Get(ctx, "aaa")
+	require.NoError(t, err)
+	assert.Equal(t, rules, url.Rules)
+
+	require.NoError(t, repo.UpdateRules(ctx, model.URLRulesEdit{UUID: "aaa", UserID: "1", Rules: []model.Rule{}}))
+	url, err = repo.Get(ctx, "aaa")
+	require.NoError(t, err)
+	assert.Nil(t, url.Rules, "empty list removes rules")
+}
@@ -4,44 +4,168 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"log/slog"
 	"os"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/ArtShib/urlshortener/internal/model"
 )
 
 // MemoryRepository структура
 type MemoryRepository struct {
-	listURLs map[string]*model.URL
-	mu       sync.RWMutex
-	fileName string
+	listURLs  map[string]*model.URL
+	userURLs  map[string][]string
+	ownerURLs map[ownerKey]string
+	index     *searchIndex
+	sequence  int64
+	clicks    *clickStore
+	mu        sync.RWMutex
+	fileName  string
+	journal   *journal
+	logger    *slog.Logger
+
+	compactCh      chan struct{}
+	stopCompact    chan struct{}
+	wg             sync.WaitGroup
+	journalMaxSize int64
+	lastSnapshot   atomic.Value
+	snapshotTail   atomic.Int64
+}
+
+// ownerKey ключ дедупликации: один оригинальный url на пользователя
+type ownerKey struct {
+	userID      string
+	originalURL string
 }
 
 // NewMemoryRepository конструктор MemoryRepository
-func NewMemoryRepository(ctx context.Context, fileName string) (*MemoryRepository, error) {
+func NewMemoryRepository(ctx context.Context, cfg *model.RepositoryConfig, log *slog.Logger) (*MemoryRepository, error) {
+	const op = "memory.NewMemoryRepository"
+	logger := log.With(
+		slog.String("op", op),
+	)
 
 	repo := &MemoryRepository{
-		listURLs: make(map[string]*model.URL),
-		fileName: fileName,
+		listURLs:  make(map[string]*model.URL),
+		userURLs:  make(map[string][]string),
+		ownerURLs: make(map[ownerKey]string),
+		index:     newSearchIndex(),
+		clicks:    newClickStore(),
+		fileName:  cfg.FileStoragePath,
+		logger:    log,
+	}
+	if repo.fileName == "" {
+		return repo, nil
 	}
 	if err := repo.LoadingRepository(ctx); err != nil {
-		return repo, err
+		logger.Error(op, "error", err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var err error
+	repo.journal, err = openJournal(repo.journalName(), cfg.FileSyncPolicy, cfg.FileSyncInterval, log)
+	if err != nil {
+		logger.Error(op, "error", err)
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	repo.startCompactor(cfg)
 	return repo, nil
 }
 
+func (r *MemoryRepository) journalName() string {
+	return r.fileName + journalSuffix
+}
+
 // Save метод для сохрания сокращенного url
 func (r *MemoryRepository) Save(ctx context.Context, url *model.URL) (*model.URL, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	_, ok := r.listURLs[url.UUID]
-	if ok {
-		return nil, model.ErrURLConflict
+	if !url.ForceNew {
+		if uuid, ok := r.ownerURLs[ownerKey{url.UserID, url.OriginalURL}]; ok {
+			existing := *r.listURLs[uuid]
+			return &existing, model.ErrURLConflict
+		}
 	}
-	r.listURLs[url.UUID] = url
+	if _, ok := r.listURLs[url.UUID]; ok {
+		return nil, model.ErrShortCodeConflict
+	}
+	if err := r.appendJournal(journalRecord{Op: opPut, URL: url}); err != nil {
+		return nil, err
+	}
+	r.put(url)
 	return url, nil
 }
 
+// put сохраняет копию url в мапе и индексах, вызывается под блокировкой
+func (r *MemoryRepository) put(url *model.URL) {
+	stored := *url
+	old, ok := r.listURLs[url.UUID]
+	if !ok && url.UserID != "" {
+		r.userURLs[url.UserID] = append(r.userURLs[url.UserID], url.UUID)
+	}
+	r.listURLs[url.UUID] = &stored
+	r.index.update(old, &stored)
+
+	key := ownerKey{url.UserID, url.OriginalURL}
+	switch {
+	case !url.ForceNew && !url.DeletedFlag && !url.ExpiredFlag:
+		if _, ok := r.ownerURLs[key]; !ok {
+			r.ownerURLs[key] = url.UUID
+		}
+	case r.ownerURLs[key] == url.UUID:
+		delete(r.ownerURLs, key)
+	}
+}
+
+// remove удаляет url из мапы и индексов, вызывается под блокировкой
+func (r *MemoryRepository) remove(uuid string) {
+	url, ok := r.listURLs[uuid]
+	if !ok {
+		return
+	}
+	delete(r.listURLs, uuid)
+	r.index.update(url, nil)
+	if key := (ownerKey{url.UserID, url.OriginalURL}); r.ownerURLs[key] == uuid {
+		delete(r.ownerURLs, key)
+	}
+	if uuids := slices.DeleteFunc(r.userURLs[url.UserID], func(u string) bool { return u == uuid }); len(uuids) > 0 {
+		r.userURLs[url.UserID] = uuids
+	} else {
+		delete(r.userURLs, url.UserID)
+	}
+}
+
+// stampDeletedAt проставляет время удаления ссылкам, удалённым до появления deleted_at,
+// чтобы они тоже попали под очистку. В файл время попадёт со следующим снимком
+func (r *MemoryRepository) stampDeletedAt(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, url := range r.listURLs {
+		if url.DeletedFlag && url.DeletedAt == nil {
+			url.DeletedAt = &now
+		}
+	}
+}
+
+// appendJournal записывает изменения в журнал, если хранилище работает с файлом
+func (r *MemoryRepository) appendJournal(records ...journalRecord) error {
+	const op = "memory.appendJournal"
+	if r.journal == nil {
+		return nil
+	}
+	if err := r.journal.Append(records...); err != nil {
+		r.logger.Error(op, "error", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	r.requestCompaction()
+	return nil
+}
+
 // Get метод получения оригинального url
 func (r *MemoryRepository) Get(ctx context.Context, uuid string) (*model.URL, error) {
 	r.mu.RLock()
@@ -50,33 +174,38 @@ func (r *MemoryRepository) Get(ctx context.Context, uuid string) (*model.URL, er
 	url, ok := r.listURLs[uuid]
 
 	if !ok {
-		return nil, errors.New("longUrl is not found")
+		return nil, model.ErrURLNotFound
 	}
 
-	return url, nil
+	result := *url
+	return &result, nil
 }
 
-// LoadingRepository метод подготовки создания файла для сохранения мапы
+// LoadingRepository метод загрузки снимка хранилища и восстановления изменений из журнала
 func (r *MemoryRepository) LoadingRepository(ctx context.Context) error {
+	const op = "memory.LoadingRepository"
 
-	info, err := os.Stat(r.fileName)
-	if os.IsNotExist(err) {
-		return err
+	data, err := os.ReadFile(r.fileName)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("%s: %w", op, err)
 	}
-	if info.Size() == 0 {
-		return errors.New("file is empty")
+	if info, err := os.Stat(r.fileName); err == nil {
+		r.lastSnapshot.Store(info.ModTime())
 	}
-	data, err := os.ReadFile(r.fileName)
-	if err != nil {
-		return err
+	if len(data) > 0 {
+		urls, err := r.unmarshalURL(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		r.loadData(ctx, urls)
 	}
 
-	urls, err := r.unmarshalURL(data)
+	records, err := readJournal(r.journalName())
 	if err != nil {
-		return err
+		return fmt.Errorf("%s: %w", op, err)
 	}
-
-	r.loadData(ctx, urls)
+	r.replay(records)
+	r.stampDeletedAt(time.Now().UTC())
 
 	return nil
 }
@@ -90,61 +219,317 @@ func (r *MemoryRepository) unmarshalURL(data []byte) ([]*model.URL, error) {
 }
 
 func (r *MemoryRepository) loadData(ctx context.Context, urls []*model.URL) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	for _, url := range urls {
-		r.Save(ctx, url)
+		r.put(url)
 	}
 }
 
-// Close метод записи и сохранения файла
+func (r *MemoryRepository) replay(records []journalRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, record := range records {
+		switch {
+		case record.Op == opPut && record.URL != nil:
+			r.put(record.URL)
+		case record.Op == opSequence:
+			r.sequence = max(r.sequence, record.Sequence)
+		case record.Op == opRemove:
+			r.remove(record.UUID)
+		}
+	}
+}
+
+// Close метод записи итогового снимка хранилища и закрытия журнала
 func (r *MemoryRepository) Close() error {
+	const op = "memory.Close"
+	if r.journal == nil {
+		return nil
+	}
+	r.stopCompactor()
 
-	if len(r.listURLs) == 0 {
-		return errors.New("listURLs is empty")
+	errSnapshot := r.snapshot()
+	errJournal := r.journal.Close()
+	if err := errors.Join(errSnapshot, errJournal); err != nil {
+		r.logger.Error(op, "error", err)
+		return fmt.Errorf("%s: %w", op, err)
 	}
+	return nil
+}
 
-	file, err := os.OpenFile(r.fileName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
-	if err != nil {
+// Ping метод проверки наличия файла журнала
+func (r *MemoryRepository) Ping(ctx context.Context) error {
+	if r.journal == nil {
+		return nil
+	}
+	_, err := os.Stat(r.journalName())
+	if os.IsNotExist(err) {
 		return err
 	}
-	defer func() error {
-		if err := file.Close(); err != nil {
-			return err
+	return nil
+}
+
+// Stats метод получения состояния хранилища
+func (r *MemoryRepository) Stats(ctx context.Context) (*model.RepositoryStats, error) {
+	r.mu.RLock()
+	stats := &model.RepositoryStats{
+		URLs: len(r.listURLs),
+	}
+	r.mu.RUnlock()
+
+	if r.journal != nil {
+		stats.JournalSize = r.journal.Size()
+	}
+	if lastSnapshot, ok := r.lastSnapshot.Load().(time.Time); ok {
+		stats.LastSnapshot = &lastSnapshot
+	}
+	return stats, nil
+}
+
+// GetBatch метод получения страницы ссылок пользователя по фильтру
+func (r *MemoryRepository) GetBatch(ctx context.Context, filter model.URLUserFilter) (model.URLUserBatch, error) {
+	r.mu.RLock()
+	uuids := r.userURLs[filter.UserID]
+	if candidates, ok := r.index.candidates(filter); ok {
+		uuids = make([]string, 0, len(candidates))
+		for uuid := range candidates {
+			uuids = append(uuids, uuid)
+		}
+	}
+	urls := make(model.URLUserBatch, 0, len(uuids))
+	for _, uuid := range uuids {
+		url, ok := r.listURLs[uuid]
+		if !ok || url.UserID != filter.UserID || !matchUserFilter(url, filter) {
+			continue
 		}
-		return nil
-	}()
+		urls = append(urls, model.URLUser{
+			UUID:            url.UUID,
+			ShortURL:        url.ShortURL,
+			OriginalURL:     url.OriginalURL,
+			CreatedAt:       url.CreatedAt,
+			DeletedFlag:     url.DeletedFlag,
+			Tags:            url.Tags,
+			Title:           url.Title,
+			Note:            url.Note,
+			PreviewRequired: url.PreviewRequired,
+			Passthrough:     url.Passthrough,
+			UTM:             url.UTM,
+		})
+	}
+	r.mu.RUnlock()
 
-	urls := make([]*model.URL, 0, len(r.listURLs))
-	for _, v := range r.listURLs {
-		urls = append(urls, v)
+	desc := filter.Sort != model.SortCreatedAsc
+	slices.SortStableFunc(urls, func(a, b model.URLUser) int {
+		c := compareUserURL(a.CreatedAt, a.UUID, b.CreatedAt, b.UUID)
+		if desc {
+			return -c
+		}
+		return c
+	})
+	if filter.Cursor != nil {
+		cursor := filter.Cursor
+		urls = slices.DeleteFunc(urls, func(url model.URLUser) bool {
+			c := compareUserURL(url.CreatedAt, url.UUID, cursor.CreatedAt, cursor.UUID)
+			return desc && c >= 0 || !desc && c <= 0
+		})
+	}
+	if filter.Limit > 0 && len(urls) > filter.Limit {
+		urls = urls[:filter.Limit]
 	}
+	return urls, nil
+}
 
-	data, err := json.Marshal(urls)
-	if err != nil {
-		return err
+// matchUserFilter проверяет url на соответствие статусу, времени создания, меткам и подстроке фильтра
+func matchUserFilter(url *model.URL, filter model.URLUserFilter) bool {
+	switch {
+	case filter.Status == model.URLStatusActive && url.DeletedFlag,
+		filter.Status == model.URLStatusDeleted && !url.DeletedFlag:
+		return false
+	case filter.CreatedAfter != nil && !url.CreatedAt.After(*filter.CreatedAfter):
+		return false
+	case filter.Query != "" && !strings.Contains(searchText(url), strings.ToLower(filter.Query)):
+		return false
 	}
-	_, err = file.Write(data)
-	if err != nil {
+	for _, tag := range filter.Tags {
+		if !slices.Contains(url.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// compareUserURL сравнивает ссылки в порядке (created_at, uuid)
+func compareUserURL(aCreated time.Time, aUUID string, bCreated time.Time, bUUID string) int {
+	if c := aCreated.Compare(bCreated); c != 0 {
+		return c
+	}
+	return strings.Compare(aUUID, bUUID)
+}
+
+// DeleteBatch метод установки признака удаления url
+func (r *MemoryRepository) DeleteBatch(ctx context.Context, deleteRequest model.URLUserRequestArray) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().UTC()
+	records := make([]journalRecord, 0, len(deleteRequest))
+	for _, req := range deleteRequest {
+		url, ok := r.listURLs[req.UUID]
+		if !ok || url.UserID != req.UserID || url.DeletedFlag {
+			continue
+		}
+		deleted := *url
+		deleted.DeletedFlag = true
+		deleted.DeletedAt = &now
+		records = append(records, journalRecord{Op: opPut, URL: &deleted})
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	if err := r.appendJournal(records...); err != nil {
 		return err
 	}
+	for _, record := range records {
+		r.put(record.URL)
+	}
 	return nil
 }
 
-// Ping метод проверки наличия файла
-func (r *MemoryRepository) Ping(ctx context.Context) error {
-	_, err := os.Stat(r.fileName)
-	if os.IsNotExist(err) {
+// RestoreBatch метод снятия признака удаления с url, удалённых позже deletedAfter.
+// Ссылка не восстанавливается, если у владельца уже есть действующая ссылка на тот же адрес
+func (r *MemoryRepository) RestoreBatch(ctx context.Context, restoreRequest model.URLUserRequestArray, deletedAfter time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	records := make([]journalRecord, 0, len(restoreRequest))
+	claimed := make(map[ownerKey]struct{})
+	for _, req := range restoreRequest {
+		url, ok := r.listURLs[req.UUID]
+		if !ok || url.UserID != req.UserID || !url.DeletedFlag {
+			continue
+		}
+		if url.DeletedAt != nil && !url.DeletedAt.After(deletedAfter) {
+			continue
+		}
+		if !url.ForceNew && !url.ExpiredFlag {
+			key := ownerKey{url.UserID, url.OriginalURL}
+			if _, ok := r.ownerURLs[key]; ok {
+				continue
+			}
+			if _, ok := claimed[key]; ok {
+				continue
+			}
+			claimed[key] = struct{}{}
+		}
+		restored := *url
+		restored.DeletedFlag = false
+		restored.DeletedAt = nil
+		records = append(records, journalRecord{Op: opPut, URL: &restored})
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	if err := r.appendJournal(records...); err != nil {
 		return err
 	}
+	for _, record := range records {
+		r.put(record.URL)
+	}
 	return nil
 }
 
-// GetBatch метод получения оригинального url по id пользователя
-func (r *MemoryRepository) GetBatch(ctx context.Context, userID string) (model.URLUserBatch, error) {
+// PurgeDeleted метод окончательного удаления не более чем limit ссылок, удалённых не позже deletedBefore.
+// Вместе со ссылкой удаляется её статистика переходов, код и адрес освобождаются
+func (r *MemoryRepository) PurgeDeleted(ctx context.Context, deletedBefore time.Time, limit int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	return nil, nil
+	records := make([]journalRecord, 0)
+	for uuid, url := range r.listURLs {
+		if len(records) >= limit {
+			break
+		}
+		if !url.DeletedFlag || url.DeletedAt == nil || url.DeletedAt.After(deletedBefore) {
+			continue
+		}
+		records = append(records, journalRecord{Op: opRemove, UUID: uuid})
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+	if err := r.appendJournal(records...); err != nil {
+		return 0, err
+	}
+	uuids := make(map[string]struct{}, len(records))
+	for _, record := range records {
+		r.remove(record.UUID)
+		uuids[record.UUID] = struct{}{}
+	}
+	r.clicks.remove(uuids)
+	return len(records), nil
 }
 
-// DeleteBatch метод установки признака удаления url
-func (r *MemoryRepository) DeleteBatch(ctx context.Context, ddeleteRequest model.URLUserRequestArray) error {
-	return nil
+// MarkExpired метод установки признака истечения срока жизни для не более чем limit ссылок
+func (r *MemoryRepository) MarkExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	records := make([]journalRecord, 0)
+	for _, url := range r.listURLs {
+		if len(records) >= limit {
+			break
+		}
+		if url.ExpiredFlag || !url.IsExpired(now) {
+			continue
+		}
+		expired := *url
+		expired.ExpiredFlag = true
+		records = append(records, journalRecord{Op: opPut, URL: &expired})
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+	if err := r.appendJournal(records...); err != nil {
+		return 0, err
+	}
+	for _, record := range records {
+		r.put(record.URL)
+	}
+	return len(records), nil
+}
+
+// ConsumeClick метод атомарного учёта перехода по ссылке с лимитом переходов.
+// Возвращает false, если лимит уже исчерпан
+func (r *MemoryRepository) ConsumeClick(ctx context.Context, uuid string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	url, ok := r.listURLs[uuid]
+	if !ok {
+		return false, model.ErrURLNotFound
+	}
+	if url.Clicks >= url.MaxClicks {
+		return false, nil
+	}
+	clicked := *url
+	clicked.Clicks++
+	if err := r.appendJournal(journalRecord{Op: opPut, URL: &clicked}); err != nil {
+		return false, err
+	}
+	r.put(&clicked)
+	return true, nil
+}
+
+// NextSequence метод получения следующего номера последовательности коротких кодов
+func (r *MemoryRepository) NextSequence(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next := r.sequence + 1
+	if err := r.appendJournal(journalRecord{Op: opSequence, Sequence: next}); err != nil {
+		return 0, err
+	}
+	r.sequence = next
+	return next, nil
 }
@@ -1 +1,534 @@
 package memory
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRepository(t *testing.T) *MemoryRepository {
+	t.Helper()
+	cfg := &model.RepositoryConfig{
+		FileStoragePath: filepath.Join(t.TempDir(), "storage.json"),
+		FileSyncPolicy:  SyncNone,
+	}
+	repo, err := NewMemoryRepository(context.Background(), cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = repo.Close() })
+	return repo
+}
+
+func TestMemoryRepository_GetBatch(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+
+	urls := []*model.URL{
+		{UUID: "aaa", ShortURL: "http://localhost/aaa", OriginalURL: "https://google.com", UserID: "1"},
+		{UUID: "bbb", ShortURL: "http://localhost/bbb", OriginalURL: "https://yandex.ru", UserID: "2"},
+		{UUID: "ccc", ShortURL: "http://localhost/ccc", OriginalURL: "https://ya.ru", UserID: "1"},
+	}
+	for _, url := range urls {
+		_, err := repo.Save(ctx, url)
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name   string
+		userID string
+		want   model.URLUserBatch
+	}{
+		{
+			name:   "user with links",
+			userID: "1",
+			want: model.URLUserBatch{
+				{UUID: "aaa", ShortURL: "http://localhost/aaa", OriginalURL: "https://google.com"},
+				{UUID: "ccc", ShortURL: "http://localhost/ccc", OriginalURL: "https://ya.ru"},
+			},
+		},
+		{
+			name:   "unknown user",
+			userID: "3",
+			want:   model.URLUserBatch{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := repo.GetBatch(ctx, model.URLUserFilter{UserID: test.userID, Sort: model.SortCreatedAsc})
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestMemoryRepository_GetBatchPagination(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i, code := range []string{"aaa", "bbb", "ccc", "ddd"} {
+		_, err := repo.Save(ctx, &model.URL{
+			UUID:        code,
+			OriginalURL: "https://example.com/" + code,
+			UserID:      "1",
+			ForceNew:    true,
+			CreatedAt:   created.Add(time.Duration(i) * time.Hour),
+		})
+		require.NoError(t, err)
+	}
+	require.NoError(t, repo.DeleteBatch(ctx, model.URLUserRequestArray{{UUID: "bbb", UserID: "1"}}))
+
+	uuids := func(urls model.URLUserBatch) []string {
+		result := make([]string, 0, len(urls))
+		for _, url := range urls {
+			result = append(result, url.UUID)
+		}
+		return result
+	}
+
+	first, err := repo.GetBatch(ctx, model.URLUserFilter{UserID: "1", Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ddd", "ccc"}, uuids(first))
+
+	cursor := &model.URLUserCursor{CreatedAt: first[1].CreatedAt, UUID: first[1].UUID}
+	second, err := repo.GetBatch(ctx, model.URLUserFilter{UserID: "1", Limit: 2, Cursor: cursor})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bbb", "aaa"}, uuids(second))
+	assert.True(t, second[0].DeletedFlag)
+
+	active, err := repo.GetBatch(ctx, model.URLUserFilter{UserID: "1", Status: model.URLStatusActive, Sort: model.SortCreatedAsc})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aaa", "ccc", "ddd"}, uuids(active))
+
+	after := created.Add(90 * time.Minute)
+	filtered, err := repo.GetBatch(ctx, model.URLUserFilter{UserID: "1", CreatedAfter: &after, Query: "EXAMPLE.com/D"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ddd"}, uuids(filtered))
+}
+
+func TestMemoryRepository_DeleteBatch(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+
+	_, err := repo.Save(ctx, &model.URL{UUID: "aaa", OriginalURL: "https://google.com", UserID: "1"})
+	require.NoError(t, err)
+	_, err = repo.Save(ctx, &model.URL{UUID: "bbb", OriginalURL: "https://yandex.ru", UserID: "2"})
+	require.NoError(t, err)
+
+	err = repo.DeleteBatch(ctx, model.URLUserRequestArray{
+		{UUID: "aaa", UserID: "1"},
+		{UUID: "bbb", UserID: "1"},
+		{UUID: "zzz", UserID: "1"},
+	})
+	require.NoError(t, err)
+
+	url, err := repo.Get(ctx, "aaa")
+	require.NoError(t, err)
+	assert.True(t, url.DeletedFlag)
+
+	url, err = repo.Get(ctx, "bbb")
+	require.NoError(t, err)
+	assert.False(t, url.DeletedFlag, "link of another user must not be deleted")
+}
+
+func TestMemoryRepository_SaveDeduplication(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+
+	_, err := repo.Save(ctx, &model.URL{UUID: "aaa", ShortURL: "http://localhost/aaa", OriginalURL: "https://google.com", UserID: "1"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		url       model.URL
+		wantShort string
+		wantErr   error
+	}{
+		{
+			name:      "same user same url",
+			url:       model.URL{UUID: "bbb", ShortURL: "http://localhost/bbb", OriginalURL: "https://google.com", UserID: "1"},
+			wantShort: "http://localhost/aaa",
+			wantErr:   model.ErrURLConflict,
+		},
+		{
+			name:      "another user same url",
+			url:       model.URL{UUID: "ccc", ShortURL: "http://localhost/ccc", OriginalURL: "https://google.com", UserID: "2"},
+			wantShort: "http://localhost/ccc",
+		},
+		{
+			name:      "force new code",
+			url:       model.URL{UUID: "ddd", ShortURL: "http://localhost/ddd", OriginalURL: "https://google.com", UserID: "1", ForceNew: true},
+			wantShort: "http://localhost/ddd",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := repo.Save(ctx, &test.url)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.NotNil(t, got)
+			assert.Equal(t, test.wantShort, got.ShortURL)
+		})
+	}
+
+	// удалённая ссылка не блокирует повторное сокращение
+	require.NoError(t, repo.DeleteBatch(ctx, model.URLUserRequestArray{{UUID: "aaa", UserID: "1"}}))
+	got, err := repo.Save(ctx, &model.URL{UUID: "eee", ShortURL: "http://localhost/eee", OriginalURL: "https://google.com", UserID: "1"})
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost/eee", got.ShortURL)
+}
+
+func TestMemoryRepository_MarkExpired(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+
+	_, err := repo.Save(ctx, &model.URL{UUID: "aaa", OriginalURL: "https://google.com", UserID: "1", ExpiresAt: &past})
+	require.NoError(t, err)
+	_, err = repo.Save(ctx, &model.URL{UUID: "bbb", OriginalURL: "https://yandex.ru", UserID: "1", ExpiresAt: &future})
+	require.NoError(t, err)
+	_, err = repo.Save(ctx, &model.URL{UUID: "ccc", OriginalURL: "https://ya.ru", UserID: "1"})
+	require.NoError(t, err)
+
+	count, err := repo.MarkExpired(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	url, err := repo.Get(ctx, "aaa")
+	require.NoError(t, err)
+	assert.True(t, url.ExpiredFlag)
+
+	// истёкшая ссылка не мешает сократить тот же url заново
+	_, err = repo.Save(ctx, &model.URL{UUID: "ddd", OriginalURL: "https://google.com", UserID: "1"})
+	require.NoError(t, err)
+
+	count, err = repo.MarkExpired(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestMemoryRepository_ConsumeClick(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+
+	_, err := repo.Save(ctx, &model.URL{UUID: "aaa", OriginalURL: "https://google.com", UserID: "1", MaxClicks: 5})
+	require.NoError(t, err)
+
+	var (
+		wg      sync.WaitGroup
+		allowed atomic.Int32
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := repo.ConsumeClick(ctx, "aaa")
+			assert.NoError(t, err)
+			if ok {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.EqualValues(t, 5, allowed.Load())
+
+	url, err := repo.Get(ctx, "aaa")
+	require.NoError(t, err)
+	assert.Equal(t, 5, url.Clicks)
+}
+
+func TestMemoryRepository_ClickStats(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+	day := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, repo.SaveClicks(ctx, []model.Click{
+		{UUID: "aaa", Time: day.Add(time.Hour), Referrer: "news.example.com", Agent: "mobile", Country: model.UnknownCountry},
+		{UUID: "aaa", Time: day.Add(90 * time.Minute), Agent: "desktop", Country: model.UnknownCountry},
+		{UUID: "aaa", Time: day.Add(26 * time.Hour), Agent: "desktop", Country: model.UnknownCountry},
+		{UUID: "bbb", Time: day, Agent: "bot", Country: model.UnknownCountry},
+	}))
+
+	stats, err := repo.ClickStats(ctx, "aaa", model.GranularityHour, day, day.Add(24*time.Hour))
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, stats.Total)
+	assert.Equal(t, []model.ClickPoint{{Time: day.Add(time.Hour), Clicks: 2}}, stats.Series)
+	assert.Equal(t, map[string]int64{"news.example.com": 1, "": 2}, stats.Referrers)
+	assert.Equal(t, map[string]int64{"mobile": 1, "desktop": 2}, stats.Agents)
+
+	stats, err = repo.ClickStats(ctx, "aaa", model.GranularityDay, day, day.Add(48*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []model.ClickPoint{{Time: day, Clicks: 2}, {Time: day.Add(24 * time.Hour), Clicks: 1}}, stats.Series)
+}
+
+func TestMemoryRepository_UpdateURL(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &model.RepositoryConfig{
+		FileStoragePath: filepath.Join(t.TempDir(), "storage.json"),
+		FileSyncPolicy:  SyncAlways,
+	}
+	repo, err := NewMemoryRepository(ctx, cfg, logger)
+	require.NoError(t, err)
+	changed := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	_, err = repo.Save(ctx, &model.URL{UUID: "aaa", OriginalURL: "https://gogle.com", UserID: "1"})
+	require.NoError(t, err)
+	_, err = repo.Save(ctx, &model.URL{UUID: "bbb", OriginalURL: "https://yandex.ru", UserID: "1"})
+	require.NoError(t, err)
+
+	edit := model.URLEdit{UUID: "aaa", UserID: "2", OriginalURL: "https://google.com", ChangedAt: changed}
+	assert.ErrorIs(t, repo.UpdateURL(ctx, edit), model.ErrURLNotFound, "only the owner can edit")
+
+	edit.UserID = "1"
+	require.NoError(t, repo.UpdateURL(ctx, edit))
+	title := "Поиск"
+	edit.OriginalURL = "https://yandex.ru"
+	edit.Meta = &model.URLMetaEdit{Title: &title}
+	assert.ErrorIs(t, repo.UpdateURL(ctx, edit), model.ErrURLConflict)
+	url, err := repo.Get(ctx, "aaa")
+	require.NoError(t, err)
+	assert.Equal(t, "https://google.com", url.OriginalURL)
+	assert.Empty(t, url.Title, "a rejected edit changes nothing")
+
+	// старый адрес освободился для дедупликации, новый занят отредактированной ссылкой
+	saved, err := repo.Save(ctx, &model.URL{UUID: "ccc", OriginalURL: "https://google.com", UserID: "1"})
+	assert.ErrorIs(t, err, model.ErrURLConflict)
+	assert.Equal(t, "aaa", saved.UUID)
+	_, err = repo.Save(ctx, &model.URL{UUID: "ddd", OriginalURL: "https://gogle.com", UserID: "1"})
+	require.NoError(t, err)
+	require.NoError(t, repo.Close())
+
+	restored, err := NewMemoryRepository(ctx, cfg, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = restored.Close() })
+
+	url, err = restored.Get(ctx, "aaa")
+	require.NoError(t, err)
+	assert.Equal(t, "https://google.com", url.OriginalURL)
+	history, err := restored.History(ctx, "aaa")
+	require.NoError(t, err)
+	assert.Equal(t, []model.URLHistoryEntry{{OriginalURL: "https://gogle.com", ChangedAt: changed}}, history)
+}
+
+func TestMemoryRepository_RestoreBatch(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+
+	for _, url := range []*model.URL{
+		{UUID: "aaa", OriginalURL: "https://google.com", UserID: "1"},
+		{UUID: "bbb", OriginalURL: "https://yandex.ru", UserID: "1"},
+		{UUID: "ccc", OriginalURL: "https://ya.ru", UserID: "1"},
+	} {
+		_, err := repo.Save(ctx, url)
+		require.NoError(t, err)
+	}
+	require.NoError(t, repo.DeleteBatch(ctx, model.URLUserRequestArray{
+		{UUID: "aaa", UserID: "1"}, {UUID: "bbb", UserID: "1"}, {UUID: "ccc", UserID: "1"},
+	}))
+	url, err := repo.Get(ctx, "aaa")
+	require.NoError(t, err)
+	require.NotNil(t, url.DeletedAt)
+
+	// после удаления адрес снова свободен, и пользователь сократил его заново
+	_, err = repo.Save(ctx, &model.URL{UUID: "ddd", OriginalURL: "https://yandex.ru", UserID: "1"})
+	require.NoError(t, err)
+
+	require.NoError(t, repo.RestoreBatch(ctx, model.URLUserRequestArray{
+		{UUID: "aaa", UserID: "2"},
+		{UUID: "bbb", UserID: "1"},
+		{UUID: "ccc", UserID: "1"},
+	}, time.Now().Add(-time.Hour)))
+
+	url, err = repo.Get(ctx, "aaa")
+	require.NoError(t, err)
+	assert.True(t, url.DeletedFlag, "only the owner can restore")
+	url, err = repo.Get(ctx, "bbb")
+	require.NoError(t, err)
+	assert.True(t, url.DeletedFlag, "restore must not clash with the active link to the same url")
+	url, err = repo.Get(ctx, "ccc")
+	require.NoError(t, err)
+	assert.False(t, url.DeletedFlag)
+	assert.Nil(t, url.DeletedAt)
+
+	require.NoError(t, repo.RestoreBatch(ctx, model.URLUserRequestArray{{UUID: "aaa", UserID: "1"}}, time.Now().Add(time.Hour)))
+	url, err = repo.Get(ctx, "aaa")
+	require.NoError(t, err)
+	assert.True(t, url.DeletedFlag, "grace period is over")
+}
+
+func TestMemoryRepository_PurgeDeleted(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &model.RepositoryConfig{
+		FileStoragePath: filepath.Join(t.TempDir(), "storage.json"),
+		FileSyncPolicy:  SyncAlways,
+	}
+	repo, err := NewMemoryRepository(ctx, cfg, logger)
+	require.NoError(t, err)
+
+	for _, url := range []*model.URL{
+		{UUID: "aaa", OriginalURL: "https://google.com", UserID: "1"},
+		{UUID: "bbb", OriginalURL: "https://yandex.ru", UserID: "1"},
+		{UUID: "ccc", OriginalURL: "https://ya.ru", UserID: "1"},
+	} {
+		_, err := repo.Save(ctx, url)
+		require.NoError(t, err)
+	}
+	require.NoError(t, repo.SaveClicks(ctx, []model.Click{{UUID: "aaa", Time: time.Now()}}))
+	require.NoError(t, repo.DeleteBatch(ctx, model.URLUserRequestArray{{UUID: "aaa", UserID: "1"}, {UUID: "bbb", UserID: "1"}}))
+
+	count, err := repo.PurgeDeleted(ctx, time.Now().Add(-time.Hour), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count, "retention period is not over yet")
+
+	count, err = repo.PurgeDeleted(ctx, time.Now(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count, "purge is bounded by the batch size")
+	count, err = repo.PurgeDeleted(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	require.NoError(t, repo.Close())
+
+	restored, err := NewMemoryRepository(ctx, cfg, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = restored.Close() })
+
+	_, err = restored.Get(ctx, "aaa")
+	assert.ErrorIs(t, err, model.ErrURLNotFound)
+	batch, err := restored.GetBatch(ctx, model.URLUserFilter{UserID: "1"})
+	require.NoError(t, err)
+	assert.Len(t, batch, 1)
+
+	// код освободился вместе со статистикой
+	_, err = restored.Save(ctx, &model.URL{UUID: "aaa", OriginalURL: "https://google.com", UserID: "2"})
+	require.NoError(t, err)
+	stats, err := repo.ClickStats(ctx, "aaa", model.GranularityDay, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, stats.Total)
+}
+
+func TestMemoryRepository_SearchIndex(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+
+	urls := []*model.URL{
+		{UUID: "aaa", OriginalURL: "https://google.com", UserID: "1", Tags: []string{"search", "work"}, Title: "Поиск"},
+		{UUID: "bbb", OriginalURL: "https://yandex.ru", UserID: "1", Tags: []string{"search"}, Note: "Quarterly report"},
+		{UUID: "ccc", OriginalURL: "https://ya.ru", UserID: "2", Tags: []string{"work"}, Title: "Report"},
+	}
+	for _, url := range urls {
+		_, err := repo.Save(ctx, url)
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name   string
+		filter model.URLUserFilter
+		want   []string
+	}{
+		{name: "one tag", filter: model.URLUserFilter{UserID: "1", Tags: []string{"search"}}, want: []string{"aaa", "bbb"}},
+		{name: "all tags", filter: model.URLUserFilter{UserID: "1", Tags: []string{"search", "work"}}, want: []string{"aaa"}},
+		{name: "tag of another user", filter: model.URLUserFilter{UserID: "2", Tags: []string{"search"}}, want: []string{}},
+		{name: "note", filter: model.URLUserFilter{UserID: "1", Query: "REPORT"}, want: []string{"bbb"}},
+		{name: "title", filter: model.URLUserFilter{UserID: "1", Query: "поис"}, want: []string{"aaa"}},
+		{name: "short query", filter: model.URLUserFilter{UserID: "1", Query: "ya"}, want: []string{"bbb"}},
+		{name: "tag and query", filter: model.URLUserFilter{UserID: "2", Tags: []string{"work"}, Query: "report"}, want: []string{"ccc"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.filter.Sort = model.SortCreatedAsc
+			got, err := repo.GetBatch(ctx, test.filter)
+			require.NoError(t, err)
+			uuids := []string{}
+			for _, url := range got {
+				uuids = append(uuids, url.UUID)
+			}
+			assert.ElementsMatch(t, test.want, uuids)
+		})
+	}
+
+	tags, title := []string{"archive"}, ""
+	assert.ErrorIs(t, repo.UpdateURL(ctx, model.URLEdit{UUID: "aaa", UserID: "2", Meta: &model.URLMetaEdit{Tags: &tags}}), model.ErrURLNotFound)
+	require.NoError(t, repo.UpdateURL(ctx, model.URLEdit{UUID: "aaa", UserID: "1", Meta: &model.URLMetaEdit{Tags: &tags, Title: &title}}))
+
+	got, err := repo.GetBatch(ctx, model.URLUserFilter{UserID: "1", Tags: []string{"work"}})
+	require.NoError(t, err)
+	assert.Empty(t, got, "old tags are removed from the index")
+	got, err = repo.GetBatch(ctx, model.URLUserFilter{UserID: "1", Query: "поиск"})
+	require.NoError(t, err)
+	assert.Empty(t, got, "old title is removed from the index")
+	got, err = repo.GetBatch(ctx, model.URLUserFilter{UserID: "1", Tags: []string{"archive"}})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "https://google.com", got[0].OriginalURL)
+	assert.Equal(t, []string{"archive"}, got[0].Tags)
+
+	passthrough, utm := true, &model.UTM{Source: "news"}
+	require.NoError(t, repo.UpdateURL(ctx, model.URLEdit{UUID: "aaa", UserID: "1", Meta: &model.URLMetaEdit{Passthrough: &passthrough, UTM: utm}}))
+	url, err := repo.Get(ctx, "aaa")
+	require.NoError(t, err)
+	assert.True(t, url.Passthrough)
+	assert.Equal(t, utm, url.UTM)
+	require.NoError(t, repo.UpdateURL(ctx, model.URLEdit{UUID: "aaa", UserID: "1", Meta: &model.URLMetaEdit{UTM: &model.UTM{}}}))
+	url, err = repo.Get(ctx, "aaa")
+	require.NoError(t, err)
+	assert.Nil(t, url.UTM, "empty utm removes the labels")
+	assert.True(t, url.Passthrough)
+}
+
+func TestMemoryRepository_Export(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i, code := range []string{"ccc", "aaa", "bbb"} {
+		_, err := repo.Save(ctx, &model.URL{
+			UUID:        code,
+			OriginalURL: "https://example.com/" + code,
+			UserID:      "1",
+			CreatedAt:   created.Add(time.Duration(i) * time.Hour),
+			Tags:        []string{"work"},
+		})
+		require.NoError(t, err)
+	}
+	_, err := repo.Save(ctx, &model.URL{UUID: "ddd", OriginalURL: "https://example.com/ddd", UserID: "2"})
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteBatch(ctx, model.URLUserRequestArray{{UUID: "aaa", UserID: "1"}}))
+	require.NoError(t, repo.SaveClicks(ctx, []model.Click{{UUID: "bbb", Time: created}, {UUID: "bbb", Time: created}}))
+
+	var got []model.URLExport
+	require.NoError(t, repo.Export(ctx, "1", func(url model.URLExport) error {
+		got = append(got, url)
+		return nil
+	}))
+	require.Len(t, got, 3)
+	assert.Equal(t, []string{"ccc", "aaa", "bbb"}, []string{got[0].UUID, got[1].UUID, got[2].UUID})
+	assert.True(t, got[1].DeletedFlag)
+	assert.NotNil(t, got[1].DeletedAt)
+	assert.Equal(t, int64(2), got[2].Clicks)
+	assert.Equal(t, []string{"work"}, got[0].Tags)
+
+	stop := errors.New("stop")
+	calls := 0
+	err = repo.Export(ctx, "1", func(url model.URLExport) error {
+		calls++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, calls)
+}
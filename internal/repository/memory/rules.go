@@ -0,0 +1,28 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// UpdateRules метод замены правил перенаправления ссылки владельцем
+func (r *MemoryRepository) UpdateRules(ctx context.Context, edit model.URLRulesEdit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	url, ok := r.listURLs[edit.UUID]
+	if !ok || url.UserID != edit.UserID || url.DeletedFlag {
+		return model.ErrURLNotFound
+	}
+	edited := *url
+	edited.Rules = nil
+	if len(edit.Rules) > 0 {
+		edited.Rules = edit.Rules
+	}
+	if err := r.appendJournal(journalRecord{Op: opPut, URL: &edited}); err != nil {
+		return err
+	}
+	r.put(&edited)
+	return nil
+}
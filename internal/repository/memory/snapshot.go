@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// startCompactor запускает фоновое создание снимков по интервалу и по размеру журнала
+func (r *MemoryRepository) startCompactor(cfg *model.RepositoryConfig) {
+	r.compactCh = make(chan struct{}, 1)
+	r.stopCompact = make(chan struct{})
+	r.journalMaxSize = cfg.JournalMaxSize
+
+	r.wg.Add(1)
+	go r.compactLoop(cfg.SnapshotInterval)
+}
+
+func (r *MemoryRepository) compactLoop(interval time.Duration) {
+	const op = "memory.compactLoop"
+	defer r.wg.Done()
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-r.stopCompact:
+			return
+		case <-tick:
+		case <-r.compactCh:
+		}
+		if r.journal.Size() <= r.snapshotTail.Load() {
+			continue
+		}
+		if err := r.snapshot(); err != nil {
+			r.logger.Error(op, "error", err)
+		}
+	}
+}
+
+// stopCompactor останавливает фоновое создание снимков
+func (r *MemoryRepository) stopCompactor() {
+	close(r.stopCompact)
+	r.wg.Wait()
+}
+
+// requestCompaction ставит снимок в очередь, если журнал превысил допустимый размер
+func (r *MemoryRepository) requestCompaction() {
+	if r.journalMaxSize <= 0 || r.journal.Size() < r.journalMaxSize {
+		return
+	}
+	select {
+	case r.compactCh <- struct{}{}:
+	default:
+	}
+}
+
+// snapshot атомарно записывает снимок хранилища и очищает журнал.
+// Запись под блокировкой на чтение не пускает изменения, попадающие в журнал.
+// Снимок хранит только ссылки, поэтому номер последовательности переносится в очищенный журнал.
+func (r *MemoryRepository) snapshot() error {
+	const op = "memory.snapshot"
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	urls := make([]*model.URL, 0, len(r.listURLs))
+	for _, v := range r.listURLs {
+		urls = append(urls, v)
+	}
+	data, err := json.Marshal(urls)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := writeFileAtomic(r.fileName, data); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := r.journal.Truncate(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if r.sequence > 0 {
+		if err := r.journal.Append(journalRecord{Op: opSequence, Sequence: r.sequence}); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	// записи, оставшиеся в журнале сразу после снимка, не требуют нового снимка
+	r.snapshotTail.Store(r.journal.Size())
+	r.lastSnapshot.Store(time.Now())
+	return nil
+}
+
+// writeFileAtomic пишет данные во временный файл и переименовывает его поверх целевого
+func writeFileAtomic(fileName string, data []byte) (err error) {
+	dir := filepath.Dir(fileName)
+	tmp, err := os.CreateTemp(dir, filepath.Base(fileName)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, os.Remove(tmp.Name()))
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return errors.Join(err, tmp.Close())
+	}
+	if err := tmp.Sync(); err != nil {
+		return errors.Join(err, tmp.Close())
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), fileName); err != nil {
+		return err
+	}
+
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	return errors.Join(d.Sync(), d.Close())
+}
@@ -0,0 +1,174 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// SaveClicks метод сохранения пачки переходов и обновления агрегатов в одной транзакции
+func (p *RepositoryPostgres) SaveClicks(ctx context.Context, clicks []model.Click) error {
+	const op = "postgres.SaveClicks"
+	logger := p.logger.With(
+		slog.String("op", op),
+	)
+	if len(clicks) == 0 {
+		return nil
+	}
+
+	var (
+		uuids     = make([]string, len(clicks))
+		times     = make([]time.Time, len(clicks))
+		referrers = make([]string, len(clicks))
+		agents    = make([]string, len(clicks))
+		countries = make([]string, len(clicks))
+	)
+	for i, click := range clicks {
+		uuids[i], times[i], referrers[i], agents[i], countries[i] =
+			click.UUID, click.Time, click.Referrer, click.Agent, click.Country
+	}
+
+	rollup := model.RollupClicks(clicks)
+	var (
+		bucketUUIDs   = make([]string, 0, len(rollup.Buckets))
+		granularities = make([]string, 0, len(rollup.Buckets))
+		starts        = make([]time.Time, 0, len(rollup.Buckets))
+		bucketCounts  = make([]int64, 0, len(rollup.Buckets))
+	)
+	for key, count := range rollup.Buckets {
+		bucketUUIDs = append(bucketUUIDs, key.UUID)
+		granularities = append(granularities, key.Granularity)
+		starts = append(starts, key.Start)
+		bucketCounts = append(bucketCounts, count)
+	}
+	var (
+		dimUUIDs   = make([]string, 0, len(rollup.Dimensions))
+		dimensions = make([]string, 0, len(rollup.Dimensions))
+		values     = make([]string, 0, len(rollup.Dimensions))
+		dimCounts  = make([]int64, 0, len(rollup.Dimensions))
+	)
+	for key, count := range rollup.Dimensions {
+		dimUUIDs = append(dimUUIDs, key.UUID)
+		dimensions = append(dimensions, key.Dimension)
+		values = append(values, key.Value)
+		dimCounts = append(dimCounts, count)
+	}
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error(op, "error", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO a_url_click (uuid, clicked_at, referrer, agent, country)
+		SELECT * FROM unnest($1::text[], $2::timestamptz[], $3::text[], $4::text[], $5::text[])`,
+		uuids, times, referrers, agents, countries); err != nil {
+		logger.Error(op, "error", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO a_url_click_rollup (uuid, granularity, bucket, clicks)
+		SELECT * FROM unnest($1::text[], $2::text[], $3::timestamptz[], $4::bigint[])
+		ON CONFLICT (uuid, granularity, bucket) DO UPDATE SET clicks = a_url_click_rollup.clicks + EXCLUDED.clicks`,
+		bucketUUIDs, granularities, starts, bucketCounts); err != nil {
+		logger.Error(op, "error", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO a_url_click_dimension (uuid, dimension, value, clicks)
+		SELECT * FROM unnest($1::text[], $2::text[], $3::text[], $4::bigint[])
+		ON CONFLICT (uuid, dimension, value) DO UPDATE SET clicks = a_url_click_dimension.clicks + EXCLUDED.clicks`,
+		dimUUIDs, dimensions, values, dimCounts); err != nil {
+		logger.Error(op, "error", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error(op, "error", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// ClickStats метод получения статистики переходов за [from, to), ряд содержит только непустые агрегаты
+func (p *RepositoryPostgres) ClickStats(ctx context.Context, uuid string, granularity string, from, to time.Time) (*model.ClickStats, error) {
+	const op = "postgres.ClickStats"
+	logger := p.logger.With(
+		slog.String("op", op),
+	)
+
+	stats := &model.ClickStats{
+		ShortCode:   uuid,
+		Granularity: granularity,
+		Referrers:   make(map[string]int64),
+		Agents:      make(map[string]int64),
+		Countries:   make(map[string]int64),
+	}
+	if err := p.db.QueryRowContext(ctx, `
+		SELECT COALESCE(sum(clicks), 0) FROM a_url_click_rollup WHERE uuid = $1 AND granularity = $2`,
+		uuid, model.GranularityDay).Scan(&stats.Total); err != nil {
+		logger.Error(op, "error", err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT bucket, clicks FROM a_url_click_rollup
+		WHERE uuid = $1 AND granularity = $2 AND bucket >= $3 AND bucket < $4
+		ORDER BY bucket`, uuid, granularity, from, to)
+	if err != nil {
+		logger.Error(op, "error", err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var point model.ClickPoint
+		if err := rows.Scan(&point.Time, &point.Clicks); err != nil {
+			logger.Error(op, "error", err)
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		point.Time = point.Time.UTC()
+		stats.Series = append(stats.Series, point)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(op, "error", err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	dimRows, err := p.db.QueryContext(ctx, `
+		SELECT dimension, value, clicks FROM a_url_click_dimension WHERE uuid = $1`, uuid)
+	if err != nil {
+		logger.Error(op, "error", err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer dimRows.Close()
+	for dimRows.Next() {
+		var (
+			dimension, value string
+			clicks           int64
+		)
+		if err := dimRows.Scan(&dimension, &value, &clicks); err != nil {
+			logger.Error(op, "error", err)
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		switch dimension {
+		case model.DimensionReferrer:
+			stats.Referrers[value] = clicks
+		case model.DimensionAgent:
+			stats.Agents[value] = clicks
+		case model.DimensionCountry:
+			stats.Countries[value] = clicks
+		}
+	}
+	if err := dimRows.Err(); err != nil {
+		logger.Error(op, "error", err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// Export метод выгрузки всех ссылок пользователя по порядку создания.
+// Строки читаются курсором по мере записи в yield, выборка целиком в память не загружается
+func (p *RepositoryPostgres) Export(ctx context.Context, userID string, yield func(model.URLExport) error) error {
+	const op = "postgres.Export"
+	logger := p.logger.With(
+		slog.String("op", op),
+	)
+
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT u.uuid, u.short_url, u.original_url, u.created_at, u.is_deleted, u.deleted_at, u.expires_at, u.is_expired,
+		       u.max_clicks, COALESCE(c.total, 0), u.tags, u.title, u.note
+		FROM a_url_short u
+		LEFT JOIN LATERAL (
+			SELECT sum(r.clicks) AS total FROM a_url_click_rollup r WHERE r.uuid = u.uuid AND r.granularity = $2
+		) c ON true
+		WHERE u.user_id = $1
+		ORDER BY u.created_at, u.uuid`, userID, model.GranularityDay)
+	if err != nil {
+		logger.Error(op, "error", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Error(op, "error", err)
+		}
+	}()
+
+	for rows.Next() {
+		var (
+			url                  model.URLExport
+			deletedAt, expiresAt sql.NullTime
+		)
+		if err := rows.Scan(&url.UUID, &url.ShortURL, &url.OriginalURL, &url.CreatedAt, &url.DeletedFlag, &deletedAt, &expiresAt,
+			&url.ExpiredFlag, &url.MaxClicks, &url.Clicks, typeMap.SQLScanner(&url.Tags), &url.Title, &url.Note); err != nil {
+			logger.Error(op, "error", err)
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if deletedAt.Valid {
+			url.DeletedAt = &deletedAt.Time
+		}
+		if expiresAt.Valid {
+			url.ExpiresAt = &expiresAt.Time
+		}
+		if err := yield(url); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(op, "error", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
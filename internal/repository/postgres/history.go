@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+const ownerURLConstraint = "uq_a_url_short_user_original_url"
+
+// UpdateURL метод изменения ссылки владельцем одной транзакцией, прежний адрес попадает в a_url_history
+func (p *RepositoryPostgres) UpdateURL(ctx context.Context, edit model.URLEdit) error {
+	const op = "postgres.UpdateURL"
+	logger := p.logger.With(
+		slog.String("op", op),
+	)
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error(op, "error", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var previous string
+	err = tx.QueryRowContext(ctx, `
+		SELECT original_url FROM a_url_short
+		WHERE uuid = $1 AND user_id = $2 AND NOT is_deleted
+		FOR UPDATE`, edit.UUID, edit.UserID).Scan(&previous)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s: %w", op, model.ErrURLNotFound)
+	}
+	if err != nil {
+		logger.Error(op, "error", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if edit.OriginalURL != "" && edit.OriginalURL != previous {
+		if _, err := tx.ExecContext(ctx, `UPDATE a_url_short SET original_url = $2 WHERE uuid = $1`,
+			edit.UUID, edit.OriginalURL); err != nil {
+			if isUniqueViolation(err, ownerURLConstraint) {
+				return fmt.Errorf("%s: %w", op, model.ErrURLConflict)
+			}
+			logger.Error(op, "error", err)
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO a_url_history (uuid, original_url, changed_at) VALUES ($1, $2, $3)`,
+			edit.UUID, previous, edit.ChangedAt); err != nil {
+			logger.Error(op, "error", err)
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if edit.Meta != nil {
+		if err := updateMeta(ctx, tx, edit.UUID, *edit.Meta); err != nil {
+			logger.Error(op, "error", err)
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		logger.Error(op, "error", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// History метод получения прежних адресов ссылки в порядке изменения
+func (p *RepositoryPostgres) History(ctx context.Context, uuid string) ([]model.URLHistoryEntry, error) {
+	const op = "postgres.History"
+	logger := p.logger.With(
+		slog.String("op", op),
+	)
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT original_url, changed_at FROM a_url_history
+		WHERE uuid = $1
+		ORDER BY changed_at, id`, uuid)
+	if err != nil {
+		logger.Error(op, "error", err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Error(op, "error", err)
+		}
+	}()
+
+	history := []model.URLHistoryEntry{}
+	for rows.Next() {
+		var entry model.URLHistoryEntry
+		if err := rows.Scan(&entry.OriginalURL, &entry.ChangedAt); err != nil {
+			logger.Error(op, "error", err)
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		history = append(history, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return history, nil
+}
+
+// updateMeta изменяет метки, заголовок, заметку и параметры перехода заблокированной ссылки, nil поля не меняются
+func updateMeta(ctx context.Context, tx *sql.Tx, uuid string, edit model.URLMetaEdit) error {
+	var tags any
+	if edit.Tags != nil {
+		tags = tagsParam(*edit.Tags)
+	}
+	var utm any
+	if edit.UTM != nil {
+		var err error
+		if utm, err = utmParam(edit.UTM); err != nil {
+			return err
+		}
+	}
+	_, err := tx.ExecContext(ctx, `
+		UPDATE a_url_short
+		SET tags = COALESCE($2::text[], tags), title = COALESCE($3, title), note = COALESCE($4, note),
+		    preview_required = COALESCE($5, preview_required), passthrough = COALESCE($6, passthrough),
+		    utm = CASE WHEN $7 THEN $8::jsonb ELSE utm END
+		WHERE uuid = $1`,
+		uuid, tags, edit.Title, edit.Note, edit.PreviewRequired, edit.Passthrough, edit.UTM != nil, utm)
+	return err
+}
@@ -0,0 +1,288 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrationLockKey ключ advisory lock, под которым применяются миграции
+const migrationLockKey int64 = 727_000_001
+
+const (
+	upSuffix   = ".up.sql"
+	downSuffix = ".down.sql"
+)
+
+// ErrNoDownMigration кастомная ошибка "down migration is missing"
+var ErrNoDownMigration = errors.New("down migration is missing")
+
+// Migration версия схемы БД
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus состояние миграции в БД
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// Migrator применяет версионные миграции, состояние хранится в таблице schema_migrations
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	logger     *slog.Logger
+}
+
+// NewMigrator конструктор Migrator
+func NewMigrator(db *sql.DB, source fs.FS, log *slog.Logger) (*Migrator, error) {
+	const op = "postgres.NewMigrator"
+	migrations, err := loadMigrations(source)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+		logger:     log,
+	}, nil
+}
+
+// loadMigrations читает файлы миграций и сортирует их по версии
+func loadMigrations(source fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(source, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, file := range files {
+		var suffix string
+		switch {
+		case strings.HasSuffix(file, upSuffix):
+			suffix = upSuffix
+		case strings.HasSuffix(file, downSuffix):
+			suffix = downSuffix
+		default:
+			return nil, fmt.Errorf("migration %s: unknown direction", file)
+		}
+
+		base := strings.TrimSuffix(path.Base(file), suffix)
+		rawVersion, name, _ := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(rawVersion, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: invalid version: %w", file, err)
+		}
+		body, err := fs.ReadFile(source, file)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if m.Name != name {
+			return nil, fmt.Errorf("migration %d: name mismatch %q and %q", version, m.Name, name)
+		}
+		if suffix == upSuffix {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d: up script is missing", m.Version)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// withLock выполняет fn на отдельном соединении под advisory lock,
+// чтобы несколько экземпляров сервиса не применяли миграции одновременно
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) (err error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, conn.Close())
+	}()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return err
+	}
+	defer func() {
+		_, unlockErr := conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+		err = errors.Join(err, unlockErr)
+	}()
+
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+						version bigint PRIMARY KEY,
+						name text not null,
+						applied_at timestamptz not null default now())`); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var (
+			version   int64
+			appliedAt time.Time
+		)
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+// runInTx выполняет скрипт миграции и изменение schema_migrations в одной транзакции
+func runInTx(ctx context.Context, conn *sql.Conn, script string, query string, args ...any) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	return tx.Commit()
+}
+
+// Up применяет все неприменённые миграции, возвращает их количество
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	const op = "postgres.Migrator.Up"
+	logger := m.logger.With(
+		slog.String("op", op),
+	)
+
+	var count int
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if err := runInTx(ctx, conn, migration.Up,
+				`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`,
+				migration.Version, migration.Name); err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			logger.Info("migration applied", "version", migration.Version, "name", migration.Name)
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error(op, "error", err)
+		return count, fmt.Errorf("%s: %w", op, err)
+	}
+	return count, nil
+}
+
+// Down откатывает steps последних применённых миграций, возвращает их количество
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	const op = "postgres.Migrator.Down"
+	logger := m.logger.With(
+		slog.String("op", op),
+	)
+
+	var count int
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, ErrNoDownMigration)
+			}
+			if err := runInTx(ctx, conn, migration.Down,
+				`DELETE FROM schema_migrations WHERE version = $1`,
+				migration.Version); err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			logger.Info("migration rolled back", "version", migration.Version, "name", migration.Name)
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error(op, "error", err)
+		return count, fmt.Errorf("%s: %w", op, err)
+	}
+	return count, nil
+}
+
+// Status возвращает состояние всех известных миграций
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	const op = "postgres.Migrator.Status"
+
+	var statuses []MigrationStatus
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		statuses = make([]MigrationStatus, 0, len(m.migrations))
+		for _, migration := range m.migrations {
+			status := MigrationStatus{
+				Version: migration.Version,
+				Name:    migration.Name,
+			}
+			if appliedAt, ok := applied[migration.Version]; ok {
+				status.Applied = true
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+	if err != nil {
+		m.logger.Error(op, "error", err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return statuses, nil
+}
@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/ArtShib/urlshortener/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   fstest.MapFS
+		versions []int64
+		wantErr  bool
+	}{
+		{
+			name: "sorted by version",
+			source: fstest.MapFS{
+				"0010_add_index.up.sql":    {Data: []byte("CREATE INDEX ...")},
+				"0002_add_column.up.sql":   {Data: []byte("ALTER TABLE ...")},
+				"0002_add_column.down.sql": {Data: []byte("ALTER TABLE ...")},
+			},
+			versions: []int64{2, 10},
+		},
+		{
+			name: "missing up script",
+			source: fstest.MapFS{
+				"0001_init.down.sql": {Data: []byte("DROP TABLE ...")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid version",
+			source: fstest.MapFS{
+				"init.up.sql": {Data: []byte("CREATE TABLE ...")},
+			},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := loadMigrations(test.source)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			versions := make([]int64, 0, len(got))
+			for _, m := range got {
+				versions = append(versions, m.Version)
+			}
+			assert.Equal(t, test.versions, versions)
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	got, err := loadMigrations(migrations.FS)
+	require.NoError(t, err)
+	require.NotEmpty(t, got)
+	for _, m := range got {
+		assert.NotEmpty(t, m.Down, "migration %d has no down script", m.Version)
+	}
+}
@@ -3,14 +3,31 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/ArtShib/urlshortener/migrations"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	_ "github.com/jackc/pgx/v5/stdlib"
 )
 
+const (
+	uniqueViolationCode = "23505"
+	shortCodeConstraint = "uq_a_url_short_uuid"
+)
+
+// searchExpr выражение поиска подстроки, совпадает с триграммным индексом idx_a_url_short_search
+const searchExpr = "lower(original_url || ' ' || title || ' ' || note)"
+
+// typeMap кодеки pgx для чтения массивов text[] через database/sql
+var typeMap = pgtype.NewMap()
+
 // RepositoryPostgres структура для работы с БД
 type RepositoryPostgres struct {
 	db     *sql.DB
@@ -73,27 +90,42 @@ func (p *RepositoryPostgres) Close() error {
 
 // Save метод для сохрания сокращенного url
 func (p *RepositoryPostgres) Save(ctx context.Context, url *model.URL) (*model.URL, error) {
-	const op = "postgres.Close"
+	const op = "postgres.Save"
 	logger := p.logger.With(
 		slog.String("op", op),
 	)
 	var isConflict bool
 	stmt, err := p.db.Prepare(`WITH inserted AS (
-						INSERT INTO a_url_short (uuid, short_url, original_url, user_id)
-						VALUES ($1, $2, $3, $4)
-						ON CONFLICT (original_url) DO NOTHING
-						RETURNING *
+						INSERT INTO a_url_short (uuid, short_url, original_url, user_id, force_new, expires_at, max_clicks, password_hash, created_at, tags, title, note, preview_required, targets, passthrough, utm)
+						VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9, $10, $11, $12, $13, $14::jsonb, $15, $16::jsonb)
+						ON CONFLICT (user_id, original_url) WHERE NOT force_new AND NOT is_deleted AND NOT is_expired DO NOTHING
+						RETURNING uuid, short_url
 					)
-					select uuid, short_url, false as is_conflict FROM inserted
-					UNION
-					SELECT uuid, short_url, true as is_conflict FROM a_url_short
-					WHERE original_url = $3 AND NOT EXISTS (SELECT 1 FROM inserted)`)
+					SELECT uuid, short_url, false AS is_conflict FROM inserted
+					UNION ALL
+					SELECT uuid, short_url, true AS is_conflict FROM a_url_short
+					WHERE user_id = $4 AND original_url = $3 AND NOT force_new AND NOT is_deleted AND NOT is_expired
+					  AND NOT EXISTS (SELECT 1 FROM inserted)`)
 
 	if err != nil {
 		logger.Error(op, "error", err)
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	if err := stmt.QueryRowContext(ctx, url.UUID, url.ShortURL, url.OriginalURL, url.UserID).Scan(&url.UUID, &url.ShortURL, &isConflict); err != nil {
+	targets, err := targetsParam(url.Targets)
+	if err != nil {
+		logger.Error(op, "error", err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	utm, err := utmParam(url.UTM)
+	if err != nil {
+		logger.Error(op, "error", err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := stmt.QueryRowContext(ctx, url.UUID, url.ShortURL, url.OriginalURL, url.UserID, url.ForceNew, url.ExpiresAt, url.MaxClicks, url.PasswordHash, url.CreatedAt,
+		tagsParam(url.Tags), url.Title, url.Note, url.PreviewRequired, targets, url.Passthrough, utm).Scan(&url.UUID, &url.ShortURL, &isConflict); err != nil {
+		if isUniqueViolation(err, shortCodeConstraint) {
+			return nil, fmt.Errorf("%s: %w", op, model.ErrShortCodeConflict)
+		}
 		logger.Error(op, "error", err)
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -103,74 +135,182 @@ func (p *RepositoryPostgres) Save(ctx context.Context, url *model.URL) (*model.U
 	return url, nil
 }
 
+// isUniqueViolation проверяет, что err нарушение уникального индекса constraint
+func isUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode && pgErr.ConstraintName == constraint
+}
+
 // Get метод получения оригинального url
 func (p *RepositoryPostgres) Get(ctx context.Context, uuid string) (*model.URL, error) {
 	const op = "postgres.Get"
 	logger := p.logger.With(
 		slog.String("op", op),
 	)
-	stmt, err := p.db.Prepare(`select uuid, short_url, original_url, user_id, is_deleted from a_url_short where uuid = $1 LIMIT 1`)
+	stmt, err := p.db.Prepare(`select uuid, short_url, original_url, user_id, is_deleted, expires_at, is_expired, max_clicks, clicks, password_hash, created_at, tags, title, note, preview_required, targets, rules, passthrough, utm from a_url_short where uuid = $1 LIMIT 1`)
 	if err != nil {
 		logger.Error(op, "error", err)
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 	row := stmt.QueryRowContext(ctx, uuid)
-	var url model.URL
-	if err := row.Scan(&url.UUID, &url.ShortURL, &url.OriginalURL, &url.UserID, &url.DeletedFlag); err != nil {
+	var (
+		url          model.URL
+		userID       sql.NullString
+		expiresAt    sql.NullTime
+		passwordHash sql.NullString
+		targets      []byte
+		rules        []byte
+		utm          []byte
+	)
+	if err := row.Scan(&url.UUID, &url.ShortURL, &url.OriginalURL, &userID, &url.DeletedFlag, &expiresAt, &url.ExpiredFlag, &url.MaxClicks, &url.Clicks, &passwordHash, &url.CreatedAt, typeMap.SQLScanner(&url.Tags), &url.Title, &url.Note, &url.PreviewRequired, &targets, &rules, &url.Passthrough, &utm); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = model.ErrURLNotFound
+		}
 		logger.Error(op, "error", err)
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	url.UserID = userID.String
+	url.PasswordHash = passwordHash.String
+	if len(targets) > 0 {
+		if err := json.Unmarshal(targets, &url.Targets); err != nil {
+			logger.Error(op, "error", err)
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if len(rules) > 0 {
+		if err := json.Unmarshal(rules, &url.Rules); err != nil {
+			logger.Error(op, "error", err)
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if len(utm) > 0 {
+		if err := json.Unmarshal(utm, &url.UTM); err != nil {
+			logger.Error(op, "error", err)
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if expiresAt.Valid {
+		url.ExpiresAt = &expiresAt.Time
+	}
 	return &url, nil
 }
 
-// LoadingRepository метод подготовки БД
+// LoadingRepository метод подготовки БД, применяет неприменённые миграции
 func (p *RepositoryPostgres) LoadingRepository(ctx context.Context) error {
-	createTable := `CREATE TABLE IF NOT EXISTS a_url_short (
-						id SERIAL PRIMARY KEY,
-						uuid text not null,
-						short_url text not null,
-						original_url text UNIQUE not null,
-						user_id text default null,
-                        is_deleted boolean default false);
-					CREATE index IF NOT EXISTS idx_short_url_uuid ON a_url_short(uuid);`
-	if _, err := p.db.ExecContext(ctx, createTable); err != nil {
+	migrator, err := NewMigrator(p.db, migrations.FS, p.logger)
+	if err != nil {
+		return err
+	}
+	if _, err := migrator.Up(ctx); err != nil {
 		return err
 	}
 	return nil
 }
 
-// GetBatch метод получения оригинального url по id пользователя
-func (p *RepositoryPostgres) GetBatch(ctx context.Context, userID string) (model.URLUserBatch, error) {
-	const op = "postgres.GetBatch"
+// OpenMigrator открывает соединение с БД для управления миграциями без запуска репозитория
+func OpenMigrator(ctx context.Context, connectionString string, log *slog.Logger) (*Migrator, func() error, error) {
+	const op = "postgres.OpenMigrator"
+	db, err := sql.Open("pgx", connectionString)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		return nil, nil, errors.Join(fmt.Errorf("%s: %w", op, err), db.Close())
+	}
+	migrator, err := NewMigrator(db, migrations.FS, log)
+	if err != nil {
+		return nil, nil, errors.Join(fmt.Errorf("%s: %w", op, err), db.Close())
+	}
+	return migrator, db.Close, nil
+}
+
+// Stats метод получения состояния хранилища
+func (p *RepositoryPostgres) Stats(ctx context.Context) (*model.RepositoryStats, error) {
+	const op = "postgres.Stats"
 	logger := p.logger.With(
 		slog.String("op", op),
 	)
-	stmt, err := p.db.Prepare(`select short_url, original_url from a_url_short where user_id = $1`)
-	if err != nil {
+	var stats model.RepositoryStats
+	if err := p.db.QueryRowContext(ctx, `select count(*) from a_url_short`).Scan(&stats.URLs); err != nil {
 		logger.Error(op, "error", err)
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	rows, err := stmt.QueryContext(ctx, userID)
+	return &stats, nil
+}
+
+// GetBatch метод получения страницы ссылок пользователя по фильтру
+func (p *RepositoryPostgres) GetBatch(ctx context.Context, filter model.URLUserFilter) (model.URLUserBatch, error) {
+	const op = "postgres.GetBatch"
+	logger := p.logger.With(
+		slog.String("op", op),
+	)
+
+	args := []interface{}{filter.UserID}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	conditions := []string{"user_id = $1"}
+	switch filter.Status {
+	case model.URLStatusActive:
+		conditions = append(conditions, "NOT is_deleted")
+	case model.URLStatusDeleted:
+		conditions = append(conditions, "is_deleted")
+	}
+	if filter.CreatedAfter != nil {
+		conditions = append(conditions, "created_at > "+arg(*filter.CreatedAfter))
+	}
+	if filter.Query != "" {
+		conditions = append(conditions, searchExpr+" LIKE "+arg("%"+escapeLike(strings.ToLower(filter.Query))+"%"))
+	}
+	if len(filter.Tags) > 0 {
+		conditions = append(conditions, "tags @> "+arg(filter.Tags)+"::text[]")
+	}
+	order, compare := "DESC", "<"
+	if filter.Sort == model.SortCreatedAsc {
+		order, compare = "ASC", ">"
+	}
+	if filter.Cursor != nil {
+		conditions = append(conditions, fmt.Sprintf("(created_at, uuid) %s (%s, %s)",
+			compare, arg(filter.Cursor.CreatedAt), arg(filter.Cursor.UUID)))
+	}
+	query := fmt.Sprintf(`
+		SELECT uuid, short_url, original_url, created_at, is_deleted, tags, title, note, preview_required, passthrough, utm
+		FROM a_url_short
+		WHERE %s
+		ORDER BY created_at %s, uuid %s`,
+		strings.Join(conditions, " AND "), order, order)
+	if filter.Limit > 0 {
+		query += " LIMIT " + arg(filter.Limit)
+	}
+
+	rows, err := p.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		logger.Error(op, "error", err)
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	if rows == nil {
-		return model.URLUserBatch{}, nil
-	}
 	defer func() {
 		if err := rows.Close(); err != nil {
 			logger.Error(op, "error", err)
 		}
 	}()
 
-	var urls model.URLUserBatch
+	urls := model.URLUserBatch{}
 	for rows.Next() {
-		var url model.URLUser
-		if err := rows.Scan(&url.ShortURL, &url.OriginalURL); err != nil {
+		var (
+			url model.URLUser
+			utm []byte
+		)
+		if err := rows.Scan(&url.UUID, &url.ShortURL, &url.OriginalURL, &url.CreatedAt, &url.DeletedFlag, typeMap.SQLScanner(&url.Tags), &url.Title, &url.Note, &url.PreviewRequired, &url.Passthrough, &utm); err != nil {
 			logger.Error(op, "error", err)
 			return nil, fmt.Errorf("%s: %w", op, err)
 		}
+		if len(utm) > 0 {
+			if err := json.Unmarshal(utm, &url.UTM); err != nil {
+				logger.Error(op, "error", err)
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+		}
 		urls = append(urls, url)
 	}
 	if err := rows.Err(); err != nil {
@@ -179,6 +319,43 @@ func (p *RepositoryPostgres) GetBatch(ctx context.Context, userID string) (model
 	return urls, nil
 }
 
+// tagsParam метки для записи в колонку tags NOT NULL: pgx передаёт nil срез как NULL
+func tagsParam(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+// targetsParam варианты ссылки для колонки targets, ссылка без вариантов хранит NULL
+func targetsParam(targets []model.Target) (any, error) {
+	if len(targets) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(targets)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// utmParam UTM метки для колонки utm, ссылка без меток хранит NULL
+func utmParam(utm *model.UTM) (any, error) {
+	if utm == nil || utm.IsZero() {
+		return nil, nil
+	}
+	data, err := json.Marshal(utm)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
 // DeleteBatch метод установки признака удаления url
 func (p *RepositoryPostgres) DeleteBatch(ctx context.Context, deleteRequest model.URLUserRequestArray) error {
 	const op = "postgres.DeleteBatch"
@@ -196,7 +373,7 @@ func (p *RepositoryPostgres) DeleteBatch(ctx context.Context, deleteRequest mode
 
 	stmt, err := p.db.Prepare(fmt.Sprintf(`
         UPDATE a_url_short 
-        SET is_deleted = true
+        SET is_deleted = true, deleted_at = now()
         FROM (VALUES %s) AS targets(uuid, user_id)
         WHERE a_url_short.uuid = targets.uuid 
           AND a_url_short.user_id = targets.user_id
@@ -213,3 +390,151 @@ func (p *RepositoryPostgres) DeleteBatch(ctx context.Context, deleteRequest mode
 
 	return nil
 }
+
+// RestoreBatch метод снятия признака удаления с url, удалённых позже deletedAfter.
+// Ссылка не восстанавливается, если у владельца уже есть действующая ссылка на тот же адрес,
+// из нескольких удалённых ссылок на один адрес восстанавливается удалённая последней
+func (p *RepositoryPostgres) RestoreBatch(ctx context.Context, restoreRequest model.URLUserRequestArray, deletedAfter time.Time) error {
+	const op = "postgres.RestoreBatch"
+	logger := p.logger.With(
+		slog.String("op", op),
+	)
+	if len(restoreRequest) == 0 {
+		return nil
+	}
+
+	values := make([]string, len(restoreRequest))
+	args := make([]interface{}, 0, len(restoreRequest)*2+1)
+	args = append(args, deletedAfter)
+	for i, req := range restoreRequest {
+		pos1, pos2 := len(args)+1, len(args)+2
+		values[i] = fmt.Sprintf("($%d, $%d)", pos1, pos2)
+		args = append(args, req.UUID, req.UserID)
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE a_url_short
+		SET is_deleted = false, deleted_at = NULL
+		FROM (
+			SELECT DISTINCT ON (u.user_id, CASE WHEN u.force_new OR u.is_expired THEN u.uuid ELSE u.original_url END) u.id
+			FROM (VALUES %s) AS targets(uuid, user_id)
+			JOIN a_url_short u ON u.uuid = targets.uuid AND u.user_id = targets.user_id
+			WHERE u.is_deleted
+			  AND (u.deleted_at IS NULL OR u.deleted_at > $1)
+			  AND (u.force_new OR u.is_expired OR NOT EXISTS (
+				SELECT 1 FROM a_url_short o
+				WHERE o.user_id = u.user_id AND o.original_url = u.original_url
+				  AND NOT o.force_new AND NOT o.is_deleted AND NOT o.is_expired))
+			ORDER BY u.user_id, CASE WHEN u.force_new OR u.is_expired THEN u.uuid ELSE u.original_url END, u.deleted_at DESC
+		) AS restored
+		WHERE a_url_short.id = restored.id`,
+		strings.Join(values, ", "))
+	if _, err := p.db.ExecContext(ctx, query, args...); err != nil {
+		logger.Error(op, "error", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// PurgeDeleted метод окончательного удаления не более чем limit ссылок, удалённых не позже deletedBefore.
+// Вместе со ссылкой удаляются её история и статистика переходов, код и адрес освобождаются
+func (p *RepositoryPostgres) PurgeDeleted(ctx context.Context, deletedBefore time.Time, limit int) (int, error) {
+	const op = "postgres.PurgeDeleted"
+	logger := p.logger.With(
+		slog.String("op", op),
+	)
+	var count int
+	err := p.db.QueryRowContext(ctx, `
+		WITH purged AS (
+			DELETE FROM a_url_short
+			WHERE id IN (
+				SELECT id FROM a_url_short
+				WHERE is_deleted AND deleted_at <= $1
+				ORDER BY deleted_at
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING uuid
+		),
+		history AS (
+			DELETE FROM a_url_history WHERE uuid IN (SELECT uuid FROM purged)
+		),
+		clicks AS (
+			DELETE FROM a_url_click WHERE uuid IN (SELECT uuid FROM purged)
+		),
+		rollups AS (
+			DELETE FROM a_url_click_rollup WHERE uuid IN (SELECT uuid FROM purged)
+		),
+		dimensions AS (
+			DELETE FROM a_url_click_dimension WHERE uuid IN (SELECT uuid FROM purged)
+		)
+		SELECT count(*) FROM purged`, deletedBefore, limit).Scan(&count)
+	if err != nil {
+		logger.Error(op, "error", err)
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return count, nil
+}
+
+// MarkExpired метод установки признака истечения срока жизни для не более чем limit ссылок
+func (p *RepositoryPostgres) MarkExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	const op = "postgres.MarkExpired"
+	logger := p.logger.With(
+		slog.String("op", op),
+	)
+	result, err := p.db.ExecContext(ctx, `
+		UPDATE a_url_short
+		SET is_expired = true
+		WHERE id IN (
+			SELECT id FROM a_url_short
+			WHERE NOT is_expired AND expires_at <= $1
+			LIMIT $2
+		)`, now, limit)
+	if err != nil {
+		logger.Error(op, "error", err)
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		logger.Error(op, "error", err)
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return int(affected), nil
+}
+
+// ConsumeClick метод атомарного учёта перехода по ссылке с лимитом переходов.
+// Возвращает false, если лимит уже исчерпан
+func (p *RepositoryPostgres) ConsumeClick(ctx context.Context, uuid string) (bool, error) {
+	const op = "postgres.ConsumeClick"
+	logger := p.logger.With(
+		slog.String("op", op),
+	)
+	var clicks int
+	err := p.db.QueryRowContext(ctx, `
+		UPDATE a_url_short
+		SET clicks = clicks + 1
+		WHERE uuid = $1 AND clicks < max_clicks
+		RETURNING clicks`, uuid).Scan(&clicks)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		logger.Error(op, "error", err)
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return true, nil
+}
+
+// NextSequence метод получения следующего номера последовательности коротких кодов
+func (p *RepositoryPostgres) NextSequence(ctx context.Context) (int64, error) {
+	const op = "postgres.NextSequence"
+	logger := p.logger.With(
+		slog.String("op", op),
+	)
+	var next int64
+	if err := p.db.QueryRowContext(ctx, `SELECT nextval('a_url_short_code_seq')`).Scan(&next); err != nil {
+		logger.Error(op, "error", err)
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return next, nil
+}
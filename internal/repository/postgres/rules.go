@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// UpdateRules метод замены правил перенаправления ссылки владельцем, ссылка без правил хранит NULL
+func (p *RepositoryPostgres) UpdateRules(ctx context.Context, edit model.URLRulesEdit) error {
+	const op = "postgres.UpdateRules"
+	logger := p.logger.With(
+		slog.String("op", op),
+	)
+
+	var rules any
+	if len(edit.Rules) > 0 {
+		data, err := json.Marshal(edit.Rules)
+		if err != nil {
+			logger.Error(op, "error", err)
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		rules = string(data)
+	}
+	result, err := p.db.ExecContext(ctx, `
+		UPDATE a_url_short SET rules = $3::jsonb
+		WHERE uuid = $1 AND user_id = $2 AND NOT is_deleted`,
+		edit.UUID, edit.UserID, rules)
+	if err != nil {
+		logger.Error(op, "error", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		logger.Error(op, "error", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, model.ErrURLNotFound)
+	}
+	return nil
+}
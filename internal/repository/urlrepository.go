@@ -4,6 +4,7 @@ import (
 	"context"
 	"log"
 	"log/slog"
+	"time"
 
 	"github.com/ArtShib/urlshortener/internal/model"
 	"github.com/ArtShib/urlshortener/internal/repository/memory"
@@ -16,17 +17,29 @@ type URLRepository interface {
 	Get(ctx context.Context, uuid string) (*model.URL, error)
 	Close() error
 	Ping(context.Context) error
-	GetBatch(ctx context.Context, userID string) (model.URLUserBatch, error)
+	Stats(ctx context.Context) (*model.RepositoryStats, error)
+	GetBatch(ctx context.Context, filter model.URLUserFilter) (model.URLUserBatch, error)
+	Export(ctx context.Context, userID string, yield func(model.URLExport) error) error
 	DeleteBatch(ctx context.Context, deleteRequest model.URLUserRequestArray) error
+	RestoreBatch(ctx context.Context, restoreRequest model.URLUserRequestArray, deletedAfter time.Time) error
+	MarkExpired(ctx context.Context, now time.Time, limit int) (int, error)
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time, limit int) (int, error)
+	ConsumeClick(ctx context.Context, uuid string) (bool, error)
+	NextSequence(ctx context.Context) (int64, error)
+	UpdateURL(ctx context.Context, edit model.URLEdit) error
+	UpdateRules(ctx context.Context, edit model.URLRulesEdit) error
+	History(ctx context.Context, uuid string) ([]model.URLHistoryEntry, error)
+	SaveClicks(ctx context.Context, clicks []model.Click) error
+	ClickStats(ctx context.Context, uuid string, granularity string, from, to time.Time) (*model.ClickStats, error)
 }
 
 // NewURLRepository конструктор создания репозитория
-func NewURLRepository(ctx context.Context, repoType string, dsnORpath string, logger *slog.Logger) (URLRepository, error) {
+func NewURLRepository(ctx context.Context, repoType string, cfg *model.RepositoryConfig, logger *slog.Logger) (URLRepository, error) {
 	switch repoType {
 	case "db":
-		return postgres.NewPostgresRepository(ctx, dsnORpath, logger)
+		return postgres.NewPostgresRepository(ctx, cfg.DatabaseDSN, logger)
 	case "file":
-		return memory.NewMemoryRepository(ctx, dsnORpath)
+		return memory.NewMemoryRepository(ctx, cfg, logger)
 	}
 	log.Fatal("not loaded repo")
 	return nil, nil
@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"log/slog"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -31,7 +32,8 @@ func TestRepo(t *testing.T) {
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			repo, _ := NewURLRepository(ctx, "file", "test.json", logger)
+			cfg := &model.RepositoryConfig{FileStoragePath: filepath.Join(t.TempDir(), "test.json")}
+			repo, _ := NewURLRepository(ctx, "file", cfg, logger)
 			_, err := repo.Save(ctx, &test.url)
 			if err != nil {
 				assert.Errorf(t, err, "Error add")
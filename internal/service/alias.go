@@ -0,0 +1,48 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// defaultAliasPattern шаблон alias, если в конфиге не задан или некорректен
+const defaultAliasPattern = `^[A-Za-z0-9_-]+$`
+
+// compileAliasPattern компилирует шаблон alias из конфига
+func compileAliasPattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		pattern = defaultAliasPattern
+	}
+	return regexp.Compile(pattern)
+}
+
+// validateAlias проверяет alias на длину, шаблон и зарезервированные слова
+func (s *URLService) validateAlias(alias string) error {
+	if s.config.AliasMinLength > 0 && len(alias) < s.config.AliasMinLength {
+		return model.NewRequestError(model.ErrInvalidAlias,
+			fmt.Sprintf("must be at least %d characters long", s.config.AliasMinLength))
+	}
+	if s.config.AliasMaxLength > 0 && len(alias) > s.config.AliasMaxLength {
+		return model.NewRequestError(model.ErrInvalidAlias,
+			fmt.Sprintf("must be at most %d characters long", s.config.AliasMaxLength))
+	}
+	if !s.aliasPattern.MatchString(alias) {
+		return model.NewRequestError(model.ErrInvalidAlias,
+			fmt.Sprintf("must match %s", s.aliasPattern.String()))
+	}
+	for _, reserved := range s.reserved {
+		if strings.EqualFold(alias, reserved) {
+			return model.NewRequestError(model.ErrInvalidAlias, fmt.Sprintf("%q is reserved", alias))
+		}
+	}
+	return nil
+}
+
+// ReserveAliases запрещает alias, совпадающие с маршрутами сервиса, в дополнение к ReservedAliases из конфига.
+// Вызывается при сборке роутера, до начала обработки запросов
+func (s *URLService) ReserveAliases(names ...string) {
+	s.reserved = append(s.reserved, names...)
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ArtShib/urlshortener/internal/httpserver"
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLService_ShortenAlias(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &model.ShortServiceConfig{
+		BaseURL:         "http://localhost:8080",
+		AliasMinLength:  3,
+		AliasMaxLength:  16,
+		ReservedAliases: []string{"promo"},
+	}
+	svc := NewURLService(&mockURLRepo{}, cfg, &mockShortener{}, logger)
+
+	tests := []struct {
+		name    string
+		alias   string
+		want    string
+		wantErr error
+	}{
+		{name: "valid", alias: "spring-sale", want: "http://localhost:8080/spring-sale"},
+		{name: "too short", alias: "ab", wantErr: model.ErrInvalidAlias},
+		{name: "too long", alias: "a-very-long-alias-value", wantErr: model.ErrInvalidAlias},
+		{name: "bad characters", alias: "sale/2025", wantErr: model.ErrInvalidAlias},
+		{name: "reserved", alias: "PROMO", wantErr: model.ErrInvalidAlias},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := svc.Shorten(context.Background(), model.RequestShortener{
+				URL:            "https://yandex.ru",
+				ShortenOptions: model.ShortenOptions{Alias: test.alias},
+			})
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+// TestURLService_RoutesReserved проверяет, что ни один маршрут роутера, включая будущие, не перекрывается alias
+func TestURLService_RoutesReserved(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := NewURLService(&mockURLRepo{}, &model.ShortServiceConfig{BaseURL: "http://localhost:8080"}, &mockShortener{}, logger)
+	router := httpserver.NewRouter(svc, logger, nil, nil, nil, nil, nil, nil, model.ImportLimits{})
+
+	routes, ok := router.(chi.Routes)
+	require.True(t, ok)
+	var segments []string
+	err := chi.Walk(routes, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		segment, _, _ := strings.Cut(strings.TrimPrefix(route, "/"), "/")
+		if segment != "" && !strings.HasPrefix(segment, "{") {
+			segments = append(segments, segment)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, segments)
+
+	for _, segment := range segments {
+		assert.ErrorIs(t, svc.validateAlias(segment), model.ErrInvalidAlias, "route %q must be reserved", segment)
+		assert.ErrorIs(t, svc.validateAlias(strings.ToUpper(segment)), model.ErrInvalidAlias, "route %q must be reserved", segment)
+	}
+	assert.NoError(t, svc.validateAlias("spring-sale"))
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+const (
+	// maxClickPoints ограничение длины временного ряда в одном ответе
+	maxClickPoints   = 1000
+	defaultHourRange = 48 * time.Hour
+	defaultDayRange  = 30 * 24 * time.Hour
+)
+
+// SaveClicks метод сервисного слоя, сохранение пачки переходов
+func (s *URLService) SaveClicks(ctx context.Context, clicks []model.Click) error {
+	const op = "URLService.SaveClicks"
+	log := s.logger.With(
+		slog.String("op", op),
+	)
+	if err := s.repo.SaveClicks(ctx, clicks); err != nil {
+		log.Error(op, "error", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// ClickStats метод сервисного слоя, статистика переходов по ссылке пользователя.
+// Чужая ссылка неотличима от несуществующей, ряд дополняется нулями за пустые интервалы
+func (s *URLService) ClickStats(ctx context.Context, req model.ClickStatsRequest) (*model.ClickStats, error) {
+	const op = "URLService.ClickStats"
+	log := s.logger.With(
+		slog.String("op", op),
+	)
+
+	granularity, from, to, err := clickStatsRange(req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	url, err := s.repo.Get(ctx, req.ShortCode)
+	if errors.Is(err, model.ErrURLNotFound) || err == nil && url.UserID != req.UserID {
+		return nil, fmt.Errorf("%s: %w", op, model.ErrURLNotFound)
+	}
+	if err != nil {
+		log.Error(op, "error", err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	stats, err := s.repo.ClickStats(ctx, url.UUID, granularity, from, to)
+	if err != nil {
+		log.Error(op, "error", err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	stats.From, stats.To = from, to
+	stats.Series = fillClickSeries(stats.Series, granularity, from, to)
+	return stats, nil
+}
+
+// clickStatsRange проверяет запрос и выравнивает [from, to) по границам агрегатов
+func clickStatsRange(req model.ClickStatsRequest, now time.Time) (string, time.Time, time.Time, error) {
+	granularity := req.Granularity
+	defaultRange := defaultDayRange
+	switch granularity {
+	case "", model.GranularityDay:
+		granularity = model.GranularityDay
+	case model.GranularityHour:
+		defaultRange = defaultHourRange
+	default:
+		return "", time.Time{}, time.Time{}, model.NewRequestError(model.ErrInvalidGranularity,
+			fmt.Sprintf("granularity must be %s or %s", model.GranularityHour, model.GranularityDay))
+	}
+
+	to := req.To
+	if to.IsZero() {
+		to = now
+	}
+	from := req.From
+	if from.IsZero() {
+		from = to.Add(-defaultRange)
+	}
+
+	step := model.GranularityStep(granularity)
+	from = model.TruncateTime(from, granularity)
+	// текущий неполный интервал входит в ответ
+	if aligned := model.TruncateTime(to, granularity); !aligned.Equal(to) {
+		to = aligned.Add(step)
+	}
+	if !from.Before(to) {
+		return "", time.Time{}, time.Time{}, model.NewRequestError(model.ErrInvalidTimeRange, "from must be before to")
+	}
+	if to.Sub(from)/step > maxClickPoints {
+		return "", time.Time{}, time.Time{}, model.NewRequestError(model.ErrInvalidTimeRange,
+			fmt.Sprintf("range must contain at most %d points", maxClickPoints))
+	}
+	return granularity, from, to, nil
+}
+
+// fillClickSeries дополняет разреженный ряд нулевыми точками
+func fillClickSeries(points []model.ClickPoint, granularity string, from, to time.Time) []model.ClickPoint {
+	counts := make(map[time.Time]int64, len(points))
+	for _, point := range points {
+		counts[point.Time.UTC()] = point.Clicks
+	}
+	step := model.GranularityStep(granularity)
+	series := make([]model.ClickPoint, 0, to.Sub(from)/step)
+	for start := from; start.Before(to); start = start.Add(step) {
+		series = append(series, model.ClickPoint{Time: start, Clicks: counts[start]})
+	}
+	return series
+}
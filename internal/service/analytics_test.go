@@ -0,0 +1,78 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClickStatsRange(t *testing.T) {
+	now := time.Date(2025, 1, 10, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		req      model.ClickStatsRequest
+		wantGran string
+		wantFrom time.Time
+		wantTo   time.Time
+		wantErr  error
+	}{
+		{
+			name:     "default day range",
+			req:      model.ClickStatsRequest{},
+			wantGran: model.GranularityDay,
+			wantFrom: time.Date(2024, 12, 11, 0, 0, 0, 0, time.UTC),
+			wantTo:   time.Date(2025, 1, 11, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "default hour range",
+			req:      model.ClickStatsRequest{Granularity: model.GranularityHour},
+			wantGran: model.GranularityHour,
+			wantFrom: time.Date(2025, 1, 8, 15, 0, 0, 0, time.UTC),
+			wantTo:   time.Date(2025, 1, 10, 16, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "unknown granularity",
+			req:     model.ClickStatsRequest{Granularity: "week"},
+			wantErr: model.ErrInvalidGranularity,
+		},
+		{
+			name:    "from after to",
+			req:     model.ClickStatsRequest{From: now, To: now.Add(-48 * time.Hour)},
+			wantErr: model.ErrInvalidTimeRange,
+		},
+		{
+			name:    "too many points",
+			req:     model.ClickStatsRequest{Granularity: model.GranularityHour, From: now.AddDate(-1, 0, 0)},
+			wantErr: model.ErrInvalidTimeRange,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gran, from, to, err := clickStatsRange(test.req, now)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.wantGran, gran)
+			assert.Equal(t, test.wantFrom, from)
+			assert.Equal(t, test.wantTo, to)
+		})
+	}
+}
+
+func TestFillClickSeries(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	series := fillClickSeries([]model.ClickPoint{{Time: from.Add(time.Hour), Clicks: 5}},
+		model.GranularityHour, from, from.Add(3*time.Hour))
+
+	assert.Equal(t, []model.ClickPoint{
+		{Time: from, Clicks: 0},
+		{Time: from.Add(time.Hour), Clicks: 5},
+		{Time: from.Add(2 * time.Hour), Clicks: 0},
+	}, series)
+}
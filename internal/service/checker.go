@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// CheckTarget метод сервисного слоя, проверка url всеми URLChecker.
+// Вызывается перед сохранением и перед переходом по уже сохранённой ссылке
+func (s *URLService) CheckTarget(ctx context.Context, originalURL string) error {
+	const op = "URLService.CheckTarget"
+	for _, checker := range s.checkers {
+		if err := checker.Check(ctx, originalURL); err != nil {
+			s.logger.Warn(op, "error", err, slog.String("url", originalURL))
+			return err
+		}
+	}
+	return nil
+}
+
+// selfLinkChecker запрещает ссылки на сам сервис, иначе короткие ссылки могут перенаправлять друг на друга по кругу
+type selfLinkChecker struct {
+	host string
+}
+
+func newSelfLinkChecker(baseURL string) *selfLinkChecker {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return &selfLinkChecker{}
+	}
+	return &selfLinkChecker{host: canonicalHost(u)}
+}
+
+// Check реализует URLChecker
+func (c *selfLinkChecker) Check(ctx context.Context, originalURL string) error {
+	if c.host == "" {
+		return nil
+	}
+	u, err := url.Parse(originalURL)
+	if err != nil {
+		return nil
+	}
+	if canonicalHost(u) == c.host {
+		return model.NewRequestError(model.ErrURLBlocked, fmt.Sprintf("links to %s are not allowed", c.host))
+	}
+	return nil
+}
+
+// canonicalHost хост с портом, порт по умолчанию опускается
+func canonicalHost(u *url.URL) string {
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && port != defaultPorts[strings.ToLower(u.Scheme)] {
+		return host + ":" + port
+	}
+	return host
+}
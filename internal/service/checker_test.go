@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type denyChecker struct{}
+
+func (denyChecker) Check(ctx context.Context, originalURL string) error {
+	if originalURL == "https://evil.com/" {
+		return model.NewRequestError(model.ErrURLBlocked, "domain evil.com is blocked")
+	}
+	return nil
+}
+
+func TestURLService_CheckTarget(t *testing.T) {
+	cfg := &model.ShortServiceConfig{BaseURL: "http://Localhost:8080"}
+	svc := NewURLService(&mockURLRepo{}, cfg, &mockShortener{}, slog.New(slog.NewTextHandler(io.Discard, nil)), denyChecker{})
+
+	tests := []struct {
+		name    string
+		url     string
+		wantErr bool
+	}{
+		{name: "allowed", url: "https://google.com", wantErr: false},
+		{name: "own base url", url: "http://localhost:8080/abc", wantErr: true},
+		{name: "same host other port", url: "http://localhost:9090/abc", wantErr: false},
+		{name: "custom checker", url: "https://EVIL.com", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := svc.save(context.Background(), test.url, model.ShortenOptions{})
+			if test.wantErr {
+				assert.ErrorIs(t, err, model.ErrURLBlocked)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
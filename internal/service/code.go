@@ -0,0 +1,60 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/ArtShib/urlshortener/internal/lib/shortener"
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+const (
+	defaultCodeLength     = 8
+	defaultCodeMaxRetries = 5
+)
+
+// saveGenerated сохраняет url со сгенерированным кодом, повторяя генерацию при коллизии кода.
+// Для стратегии random повторная коллизия в рамках одного сохранения означает, что пространство кодов
+// текущей длины заполнено, и длина кода увеличивается для всех следующих ссылок. У hash и counter
+// коллизия не говорит о заполненности: hash повторяет попытку со случайной солью, counter берёт следующий номер
+func (s *URLService) saveGenerated(ctx context.Context, urlModel *model.URL) (*model.URL, error) {
+	const op = "URLService.saveGenerated"
+	log := s.logger.With(
+		slog.String("op", op),
+	)
+
+	retries := cmp.Or(s.config.CodeMaxRetries, defaultCodeMaxRetries)
+	for attempt := 0; attempt < retries; attempt++ {
+		length := int(s.codeLength.Load())
+		uuid, err := s.shortener.GenerateUUID(ctx, urlModel.OriginalURL, length, attempt)
+		if err != nil {
+			return nil, err
+		}
+		urlModel.UUID = uuid
+		urlModel.ShortURL = s.shortener.GenerateShortURL(s.config.BaseURL, uuid)
+
+		saved, err := s.repo.Save(ctx, urlModel)
+		if !errors.Is(err, model.ErrShortCodeConflict) {
+			return saved, err
+		}
+		log.Warn("short code collision", "uuid", uuid, "attempt", attempt)
+		if attempt > 0 && s.shortener.Strategy() == shortener.StrategyRandom {
+			s.growCodeLength(length)
+		}
+	}
+	return nil, fmt.Errorf("%w after %d attempts", model.ErrShortCodeExhausted, retries)
+}
+
+// growCodeLength увеличивает длину кода на единицу, если её ещё не увеличил другой запрос
+func (s *URLService) growCodeLength(current int) {
+	maxLength := s.config.CodeMaxLength
+	if maxLength > 0 && current >= maxLength {
+		return
+	}
+	if s.codeLength.CompareAndSwap(int32(current), int32(current+1)) {
+		s.logger.Info("short code length increased", "length", current+1)
+	}
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/ArtShib/urlshortener/internal/lib/shortener"
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/ArtShib/urlshortener/internal/repository/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// crowdedRepo отвергает коды короче minLength, имитируя заполненное пространство кодов
+type crowdedRepo struct {
+	mockURLRepo
+	minLength int
+}
+
+func (r *crowdedRepo) Save(ctx context.Context, url *model.URL) (*model.URL, error) {
+	if len(url.UUID) < r.minLength {
+		return nil, model.ErrShortCodeConflict
+	}
+	return url, nil
+}
+
+type lengthShortener struct {
+	mockShortener
+}
+
+func (s *lengthShortener) GenerateUUID(ctx context.Context, originalURL string, length int, attempt int) (string, error) {
+	return strings.Repeat("a", length), nil
+}
+
+func TestURLService_SaveGenerated(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	tests := []struct {
+		name       string
+		minLength  int
+		wantErr    error
+		wantLength int32
+	}{
+		{name: "no collision", minLength: 8, wantLength: 8},
+		{name: "code length grows", minLength: 10, wantLength: 10},
+		{name: "length is capped", minLength: 20, wantErr: model.ErrShortCodeExhausted, wantLength: 12},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := &model.ShortServiceConfig{
+				BaseURL:        "http://localhost:8080",
+				CodeLength:     8,
+				CodeMaxLength:  12,
+				CodeMaxRetries: 10,
+			}
+			svc := NewURLService(&crowdedRepo{minLength: test.minLength}, cfg, &lengthShortener{}, logger)
+
+			url, err := svc.save(context.Background(), "https://google.com", model.ShortenOptions{})
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Len(t, url.UUID, int(test.wantLength))
+			}
+			assert.Equal(t, test.wantLength, svc.codeLength.Load())
+		})
+	}
+}
+
+func TestURLService_SaveGenerated_Hash(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repo, err := memory.NewMemoryRepository(ctx, &model.RepositoryConfig{}, logger)
+	require.NoError(t, err)
+	hash, err := shortener.NewShortener(shortener.StrategyHash, "", nil)
+	require.NoError(t, err)
+	cfg := &model.ShortServiceConfig{
+		BaseURL:        "http://localhost:8080",
+		CodeLength:     8,
+		CodeMaxLength:  16,
+		CodeMaxRetries: 5,
+	}
+	svc := NewURLService(repo, cfg, hash, logger)
+
+	codes := map[string]bool{}
+	for i := 0; i < 20; i++ {
+		url, err := svc.save(ctx, "https://google.com", model.ShortenOptions{MaxClicks: 1})
+		require.NoError(t, err, "save %d", i+1)
+		assert.Len(t, url.UUID, 8)
+		codes[url.UUID] = true
+	}
+	assert.Len(t, codes, 20, "each link gets its own code")
+	assert.EqualValues(t, 8, svc.codeLength.Load(), "collisions of one url do not grow the code length")
+}
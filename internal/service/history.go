@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// UpdateURL метод сервисного слоя, изменение адреса, меток, заголовка, заметки и параметров перехода ссылки её владельцем.
+// Новый адрес проходит ту же нормализацию и проверки, что и при сокращении.
+// Адрес и остальные поля меняются одним вызовом репозитория
+func (s *URLService) UpdateURL(ctx context.Context, userID, shortCode string, req model.RequestEditURL) (*model.URL, error) {
+	const op = "URLService.UpdateURL"
+	log := s.logger.With(
+		slog.String("op", op),
+	)
+
+	meta, err := metaEdit(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.OriginalURL == "" && meta == nil {
+		return nil, model.NewRequestError(model.ErrInvalidMetadata, "nothing to update")
+	}
+
+	edit := model.URLEdit{
+		UUID:      shortCode,
+		UserID:    userID,
+		ChangedAt: time.Now().UTC().Truncate(time.Microsecond),
+		Meta:      meta,
+	}
+	if req.OriginalURL != "" {
+		current, err := s.repo.Get(ctx, shortCode)
+		if err != nil {
+			if !errors.Is(err, model.ErrURLNotFound) {
+				log.Error(op, "error", err)
+			}
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if current.UserID == userID && current.IsSplit() {
+			// адрес ссылки с вариантами это её первый вариант, менять его отдельно нельзя
+			return nil, model.NewRequestError(model.ErrInvalidTargets, "url of a link with targets cannot be changed")
+		}
+		originalURL, err := s.normalizeURL(req.OriginalURL)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.CheckTarget(ctx, originalURL); err != nil {
+			return nil, err
+		}
+		edit.OriginalURL = originalURL
+	}
+	if err := s.repo.UpdateURL(ctx, edit); err != nil {
+		if !errors.Is(err, model.ErrURLNotFound) && !errors.Is(err, model.ErrURLConflict) {
+			log.Error(op, "error", err)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	url, err := s.repo.Get(ctx, shortCode)
+	if err != nil {
+		log.Error(op, "error", err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return url, nil
+}
+
+// History метод сервисного слоя, прежние адреса ссылки пользователя.
+// Чужая ссылка неотличима от несуществующей
+func (s *URLService) History(ctx context.Context, userID, shortCode string) ([]model.URLHistoryEntry, error) {
+	const op = "URLService.History"
+	log := s.logger.With(
+		slog.String("op", op),
+	)
+
+	url, err := s.repo.Get(ctx, shortCode)
+	if errors.Is(err, model.ErrURLNotFound) || err == nil && url.UserID != userID {
+		return nil, fmt.Errorf("%s: %w", op, model.ErrURLNotFound)
+	}
+	if err != nil {
+		log.Error(op, "error", err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	history, err := s.repo.History(ctx, url.UUID)
+	if err != nil {
+		log.Error(op, "error", err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return history, nil
+}
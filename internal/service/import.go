@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// ImportURLs метод сервисного слоя, сохраняет пачку строк импорта от имени пользователя из контекста.
+// Ошибка строки не прерывает импорт и возвращается в её результате
+func (s *URLService) ImportURLs(ctx context.Context, rows []model.ImportRow) []model.ImportResult {
+	const op = "URLService.ImportURLs"
+	log := s.logger.With(
+		slog.String("op", op),
+	)
+	ctx, cancel := context.WithTimeout(ctx, longOperationTimeout)
+	defer cancel()
+
+	results := make([]model.ImportResult, len(rows))
+	batch := make(model.RequestShortenerBatchArray, 0, len(rows))
+	index := make([]int, 0, len(rows))
+	for i, row := range rows {
+		results[i] = model.ImportResult{Line: row.Line, OriginalURL: row.OriginalURL}
+		opts, err := importOptions(row)
+		if err != nil {
+			results[i].Status, results[i].Err = model.ImportStatusError, err
+			continue
+		}
+		batch = append(batch, model.RequestShortenerBatch{OriginalURL: row.OriginalURL, ShortenOptions: opts})
+		index = append(index, i)
+	}
+
+	s.saveBatch(ctx, batch, func(i int, url *model.URL, err error) bool {
+		result := &results[index[i]]
+		if url == nil || err != nil && !errors.Is(err, model.ErrURLConflict) {
+			var reqErr *model.RequestError
+			if !errors.As(err, &reqErr) {
+				log.Error(op, "error", err)
+			}
+			result.Status, result.Err = model.ImportStatusError, err
+			return true
+		}
+		result.Status = model.ImportStatusCreated
+		if err != nil {
+			result.Status = model.ImportStatusExists
+		}
+		result.Code, result.ShortURL = url.UUID, url.ShortURL
+		return true
+	})
+	return results
+}
+
+// importOptions параметры сокращения из строки импорта
+func importOptions(row model.ImportRow) (model.ShortenOptions, error) {
+	opts := model.ShortenOptions{Alias: row.Alias, Tags: row.Tags}
+	if row.ExpiresAt != "" {
+		expiresAt, err := time.Parse(time.RFC3339, row.ExpiresAt)
+		if err != nil {
+			return opts, model.NewRequestError(model.ErrInvalidExpiry, fmt.Sprintf("expires_at %q must be in RFC 3339 format", row.ExpiresAt))
+		}
+		opts.ExpiresAt = &expiresAt
+	}
+	return opts, nil
+}
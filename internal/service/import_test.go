@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURLService_ImportURLs(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &model.ShortServiceConfig{BaseURL: "http://localhost:8080", AliasMinLength: 3, AliasMaxLength: 16}
+	svc := NewURLService(&mockURLRepo{}, cfg, &mockShortener{}, logger)
+	ctx := context.WithValue(context.Background(), model.UserIDKey, "1")
+
+	results := svc.ImportURLs(ctx, []model.ImportRow{
+		{Line: 2, OriginalURL: "https://yandex.ru"},
+		{Line: 3, OriginalURL: "ftp://yandex.ru"},
+		{Line: 4, OriginalURL: "https://google.com", ExpiresAt: "tomorrow"},
+		{Line: 5, OriginalURL: "https://google.com", Alias: "spring-sale", Tags: []string{"Sale"}},
+	})
+
+	assert.Len(t, results, 4)
+	assert.Equal(t, model.ImportResult{Line: 2, OriginalURL: "https://yandex.ru", Status: model.ImportStatusCreated,
+		Code: "a7v4M9PY", ShortURL: "http://localhost:8080/a7v4M9PY"}, results[0])
+	assert.Equal(t, model.ImportStatusError, results[1].Status)
+	assert.ErrorIs(t, results[1].Err, model.ErrInvalidURL)
+	assert.Equal(t, model.ImportStatusError, results[2].Status)
+	assert.ErrorIs(t, results[2].Err, model.ErrInvalidExpiry)
+	assert.Equal(t, model.ImportResult{Line: 5, OriginalURL: "https://google.com", Status: model.ImportStatusCreated,
+		Code: "spring-sale", ShortURL: "http://localhost:8080/spring-sale"}, results[3])
+}
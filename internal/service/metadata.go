@@ -0,0 +1,112 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+const (
+	maxTags        = 20
+	maxTagLength   = 64
+	maxTitleLength = 200
+	maxNoteLength  = 2000
+)
+
+// normalizeTags приводит метки к нижнему регистру, убирает повторы и сортирует.
+// Метка состоит из букв, цифр и символов - _ . : /
+func normalizeTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, model.NewRequestError(model.ErrInvalidMetadata, fmt.Sprintf("tag must be 1 to %d characters long", maxTagLength))
+		}
+		if strings.IndexFunc(tag, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-_.:/", r)
+		}) >= 0 {
+			return nil, model.NewRequestError(model.ErrInvalidMetadata, fmt.Sprintf("tag %q contains invalid characters", tag))
+		}
+		normalized = append(normalized, tag)
+	}
+	slices.Sort(normalized)
+	normalized = slices.Compact(normalized)
+	if len(normalized) > maxTags {
+		return nil, model.NewRequestError(model.ErrInvalidMetadata, fmt.Sprintf("at most %d tags are allowed", maxTags))
+	}
+	return normalized, nil
+}
+
+// normalizeText обрезает пробелы заголовка или заметки и проверяет длину
+func normalizeText(name, text string, maxLength int) (string, error) {
+	text = strings.TrimSpace(text)
+	if utf8.RuneCountInString(text) > maxLength {
+		return "", model.NewRequestError(model.ErrInvalidMetadata, fmt.Sprintf("%s must be at most %d characters long", name, maxLength))
+	}
+	return text, nil
+}
+
+// applyMetadata проверяет метки, заголовок и заметку из параметров сокращения и переносит их в url
+func applyMetadata(url *model.URL, opts model.ShortenOptions) error {
+	var err error
+	if url.Tags, err = normalizeTags(opts.Tags); err != nil {
+		return err
+	}
+	if url.Title, err = normalizeText("title", opts.Title, maxTitleLength); err != nil {
+		return err
+	}
+	if url.Note, err = normalizeText("note", opts.Note, maxNoteLength); err != nil {
+		return err
+	}
+	return nil
+}
+
+// metaEdit проверяет изменения меток, заголовка, заметки и параметров перехода из запроса на изменение ссылки
+func metaEdit(req model.RequestEditURL) (*model.URLMetaEdit, error) {
+	if req.Tags == nil && req.Title == nil && req.Note == nil && req.Preview == nil && req.Passthrough == nil && req.UTM == nil {
+		return nil, nil
+	}
+	edit := &model.URLMetaEdit{PreviewRequired: req.Preview, Passthrough: req.Passthrough}
+	if req.UTM != nil {
+		utm, err := normalizeUTM(req.UTM)
+		if err != nil {
+			return nil, err
+		}
+		if utm == nil {
+			utm = &model.UTM{}
+		}
+		edit.UTM = utm
+	}
+	if req.Tags != nil {
+		tags, err := normalizeTags(*req.Tags)
+		if err != nil {
+			return nil, err
+		}
+		if tags == nil {
+			tags = []string{}
+		}
+		edit.Tags = &tags
+	}
+	if req.Title != nil {
+		title, err := normalizeText("title", *req.Title, maxTitleLength)
+		if err != nil {
+			return nil, err
+		}
+		edit.Title = &title
+	}
+	if req.Note != nil {
+		note, err := normalizeText("note", *req.Note, maxNoteLength)
+		if err != nil {
+			return nil, err
+		}
+		edit.Note = &note
+	}
+	return edit, nil
+}
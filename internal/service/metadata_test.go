@@ -0,0 +1,74 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "empty", tags: nil, want: nil},
+		{name: "lowercase sorted unique", tags: []string{" Work ", "docs", "work", "team/q1"}, want: []string{"docs", "team/q1", "work"}},
+		{name: "unicode letters", tags: []string{"Отчёт"}, want: []string{"отчёт"}},
+		{name: "blank tag", tags: []string{" "}, wantErr: true},
+		{name: "space inside", tags: []string{"two words"}, wantErr: true},
+		{name: "too long", tags: []string{strings.Repeat("a", maxTagLength+1)}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := normalizeTags(test.tags)
+			if test.wantErr {
+				assert.ErrorIs(t, err, model.ErrInvalidMetadata)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestMetaEdit(t *testing.T) {
+	edit, err := metaEdit(model.RequestEditURL{OriginalURL: "https://google.com"})
+	require.NoError(t, err)
+	assert.Nil(t, edit, "only the target changes")
+
+	empty, title := []string{}, "  Docs  "
+	edit, err = metaEdit(model.RequestEditURL{Tags: &empty, Title: &title})
+	require.NoError(t, err)
+	require.NotNil(t, edit.Tags)
+	assert.Equal(t, []string{}, *edit.Tags, "empty list clears tags")
+	assert.Equal(t, "Docs", *edit.Title)
+	assert.Nil(t, edit.Note)
+	assert.Nil(t, edit.PreviewRequired)
+
+	preview := true
+	edit, err = metaEdit(model.RequestEditURL{Preview: &preview})
+	require.NoError(t, err)
+	require.NotNil(t, edit.PreviewRequired, "preview flag alone is an edit")
+	assert.True(t, *edit.PreviewRequired)
+
+	edit, err = metaEdit(model.RequestEditURL{UTM: &model.UTM{Source: "  "}})
+	require.NoError(t, err)
+	require.NotNil(t, edit.UTM, "blank utm clears the labels")
+	assert.True(t, edit.UTM.IsZero())
+
+	edit, err = metaEdit(model.RequestEditURL{UTM: &model.UTM{Source: " news ", Campaign: "spring"}})
+	require.NoError(t, err)
+	assert.Equal(t, &model.UTM{Source: "news", Campaign: "spring"}, edit.UTM)
+
+	_, err = metaEdit(model.RequestEditURL{UTM: &model.UTM{Term: strings.Repeat("x", maxUTMLength+1)}})
+	assert.ErrorIs(t, err, model.ErrInvalidRedirect)
+
+	note := strings.Repeat("x", maxNoteLength+1)
+	_, err = metaEdit(model.RequestEditURL{Note: &note})
+	assert.ErrorIs(t, err, model.ErrInvalidMetadata)
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// defaultSchemes схемы, разрешённые, если в конфиге список не задан
+var defaultSchemes = []string{"http", "https"}
+
+// defaultPorts порты, которые не пишутся в каноническом url
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// normalizeURL проверяет url и приводит его к каноническому виду, по которому идёт дедупликация:
+// без пробелов по краям, хост в нижнем регистре, без порта по умолчанию и фрагмента
+func (s *URLService) normalizeURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", model.NewRequestError(model.ErrInvalidURL, "url must not be empty")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", model.NewRequestError(model.ErrInvalidURL, "url is malformed")
+	}
+
+	schemes := s.config.AllowedSchemes
+	if len(schemes) == 0 {
+		schemes = defaultSchemes
+	}
+	if u.Scheme == "" || !slices.ContainsFunc(schemes, func(scheme string) bool {
+		return strings.EqualFold(scheme, u.Scheme)
+	}) {
+		return "", model.NewRequestError(model.ErrInvalidURL,
+			fmt.Sprintf("scheme must be one of %s", strings.Join(schemes, ", ")))
+	}
+	if u.Opaque != "" || u.Hostname() == "" {
+		return "", model.NewRequestError(model.ErrInvalidURL, "url must have a host")
+	}
+
+	host, port := strings.ToLower(u.Hostname()), u.Port()
+	if port == defaultPorts[u.Scheme] {
+		port = ""
+	}
+	switch {
+	case port != "":
+		u.Host = net.JoinHostPort(host, port)
+	case strings.Contains(host, ":"):
+		u.Host = "[" + host + "]"
+	default:
+		u.Host = host
+	}
+
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	u.Fragment, u.RawFragment = "", ""
+	if s.config.SortQueryParams && u.RawQuery != "" {
+		u.RawQuery = u.Query().Encode()
+	}
+	return u.String(), nil
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLService_NormalizeURL(t *testing.T) {
+	svc := NewURLService(nil, &model.ShortServiceConfig{SortQueryParams: true}, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{name: "trim and root path", raw: "  https://Google.COM  ", want: "https://google.com/"},
+		{name: "default port and fragment", raw: "http://ya.ru:80/a/b#top", want: "http://ya.ru/a/b"},
+		{name: "custom port kept", raw: "https://ya.ru:8443/a", want: "https://ya.ru:8443/a"},
+		{name: "ipv6 host", raw: "http://[::1]:80/", want: "http://[::1]/"},
+		{name: "query sorted", raw: "https://ya.ru/search?q=go&a=1", want: "https://ya.ru/search?a=1&q=go"},
+		{name: "upper case scheme", raw: "HTTPS://ya.ru/Path", want: "https://ya.ru/Path"},
+		{name: "empty", raw: "   ", wantErr: true},
+		{name: "javascript scheme", raw: "javascript:alert(1)", wantErr: true},
+		{name: "no scheme", raw: "foo", wantErr: true},
+		{name: "no host", raw: "https:///path", wantErr: true},
+		{name: "ftp not allowed", raw: "ftp://ya.ru/file", wantErr: true},
+		{name: "malformed", raw: "http://ya ru/", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := svc.normalizeURL(test.raw)
+			if test.wantErr {
+				assert.ErrorIs(t, err, model.ErrInvalidURL)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/ArtShib/urlshortener/internal/lib/password"
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// maxPasswordLength ограничение длины пароля ссылки
+const maxPasswordLength = 128
+
+// hashPassword проверяет пароль из запроса и возвращает его хеш, пустой пароль означает открытую ссылку
+func hashPassword(plain string) (string, error) {
+	if plain == "" {
+		return "", nil
+	}
+	if len(plain) > maxPasswordLength {
+		return "", model.NewRequestError(model.ErrInvalidPassword,
+			fmt.Sprintf("password must be at most %d characters long", maxPasswordLength))
+	}
+	return password.Hash(plain)
+}
+
+// Unlock метод сервисного слоя, проверка пароля защищённой ссылки.
+// Неудачные попытки ограничиваются по короткому коду
+func (s *URLService) Unlock(ctx context.Context, url *model.URL, plain string) error {
+	const op = "URLService.Unlock"
+	log := s.logger.With(
+		slog.String("op", op),
+	)
+	if !url.IsProtected() {
+		return nil
+	}
+	if !s.attempts.Take(url.UUID) {
+		log.Warn(op, "error", model.ErrTooManyAttempts, "uuid", url.UUID)
+		return fmt.Errorf("%s: %w", op, model.ErrTooManyAttempts)
+	}
+	if !password.Verify(url.PasswordHash, plain) {
+		return fmt.Errorf("%s: %w", op, model.ErrWrongPassword)
+	}
+	s.attempts.Reset(url.UUID)
+	return nil
+}
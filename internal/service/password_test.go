@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/lib/password"
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLService_Unlock(t *testing.T) {
+	ctx := context.Background()
+	svc := NewURLService(nil, &model.ShortServiceConfig{
+		PasswordMaxAttempts:   2,
+		PasswordAttemptWindow: time.Minute,
+	}, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	hash, err := password.Hash("secret")
+	require.NoError(t, err)
+	url := &model.URL{UUID: "aaa", PasswordHash: hash}
+
+	require.NoError(t, svc.Unlock(ctx, url, "secret"))
+	assert.ErrorIs(t, svc.Unlock(ctx, url, "guess"), model.ErrWrongPassword)
+	assert.ErrorIs(t, svc.Unlock(ctx, url, "guess"), model.ErrWrongPassword)
+	assert.ErrorIs(t, svc.Unlock(ctx, url, "secret"), model.ErrTooManyAttempts,
+		"correct password must be refused while the code is locked")
+
+	assert.NoError(t, svc.Unlock(ctx, &model.URL{UUID: "bbb"}, ""), "open link needs no password")
+}
+
+func TestURLService_UnlockConcurrent(t *testing.T) {
+	ctx := context.Background()
+	svc := NewURLService(nil, &model.ShortServiceConfig{
+		PasswordMaxAttempts:   3,
+		PasswordAttemptWindow: time.Minute,
+	}, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	hash, err := password.Hash("secret")
+	require.NoError(t, err)
+	url := &model.URL{UUID: "aaa", PasswordHash: hash}
+
+	var wrong atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if errors.Is(svc.Unlock(ctx, url, "guess"), model.ErrWrongPassword) {
+				wrong.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.EqualValues(t, 3, wrong.Load(), "only the allowed number of guesses is checked")
+}
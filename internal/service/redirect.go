@@ -0,0 +1,40 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+const maxUTMLength = 200
+
+// normalizeUTM обрезает пробелы UTM меток и проверяет длину. Без заданных меток возвращает nil
+func normalizeUTM(utm *model.UTM) (*model.UTM, error) {
+	if utm == nil {
+		return nil, nil
+	}
+	normalized := *utm
+	for _, field := range []*string{&normalized.Source, &normalized.Medium, &normalized.Campaign, &normalized.Term, &normalized.Content} {
+		*field = strings.TrimSpace(*field)
+		if utf8.RuneCountInString(*field) > maxUTMLength {
+			return nil, model.NewRequestError(model.ErrInvalidRedirect, fmt.Sprintf("utm values must be at most %d characters long", maxUTMLength))
+		}
+	}
+	if normalized.IsZero() {
+		return nil, nil
+	}
+	return &normalized, nil
+}
+
+// applyRedirect переносит в url перенос параметров запроса и UTM метки из параметров сокращения
+func applyRedirect(url *model.URL, opts model.ShortenOptions) error {
+	utm, err := normalizeUTM(opts.UTM)
+	if err != nil {
+		return err
+	}
+	url.Passthrough = opts.Passthrough
+	url.UTM = utm
+	return nil
+}
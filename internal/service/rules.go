@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/lib/useragent"
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+const (
+	maxRules      = 20
+	maxRuleParams = 10
+)
+
+var (
+	// ruleDevices классы и платформы устройств, доступные в правилах
+	ruleDevices = []string{
+		useragent.PlatformIOS, useragent.PlatformAndroid,
+		useragent.ClassMobile, useragent.ClassTablet, useragent.ClassDesktop, useragent.ClassBot,
+	}
+	// languagePattern язык правила: основной тег с необязательными уточнениями, например pt или pt-br
+	languagePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+)
+
+// normalizeRules проверяет правила перенаправления: адреса проходят ту же нормализацию и проверки,
+// что и при сокращении, у каждого правила есть хотя бы одно условие
+func (s *URLService) normalizeRules(ctx context.Context, rules []model.Rule) ([]model.Rule, error) {
+	if len(rules) > maxRules {
+		return nil, model.NewRequestError(model.ErrInvalidRules, fmt.Sprintf("at most %d rules are allowed", maxRules))
+	}
+	normalized := make([]model.Rule, 0, len(rules))
+	for i, rule := range rules {
+		rule, err := s.normalizeRule(ctx, rule)
+		if err != nil {
+			var reqErr *model.RequestError
+			if errors.As(err, &reqErr) {
+				err = model.NewRequestError(reqErr.Err, fmt.Sprintf("rule %d: %s", i+1, reqErr.Reason))
+			}
+			return nil, err
+		}
+		normalized = append(normalized, rule)
+	}
+	return normalized, nil
+}
+
+func (s *URLService) normalizeRule(ctx context.Context, rule model.Rule) (model.Rule, error) {
+	rule.Device = strings.ToLower(strings.TrimSpace(rule.Device))
+	if rule.Device != "" && !slices.Contains(ruleDevices, rule.Device) {
+		return rule, model.NewRequestError(model.ErrInvalidRules,
+			fmt.Sprintf("device must be one of %s", strings.Join(ruleDevices, ", ")))
+	}
+	rule.Language = strings.ToLower(strings.TrimSpace(rule.Language))
+	if rule.Language != "" && !languagePattern.MatchString(rule.Language) {
+		return rule, model.NewRequestError(model.ErrInvalidRules, "language must be a language tag such as en or pt-br")
+	}
+	if len(rule.Query) > maxRuleParams {
+		return rule, model.NewRequestError(model.ErrInvalidRules, fmt.Sprintf("at most %d query params are allowed", maxRuleParams))
+	}
+	for name := range rule.Query {
+		if name == "" {
+			return rule, model.NewRequestError(model.ErrInvalidRules, "query param name must not be empty")
+		}
+	}
+	if len(rule.Query) == 0 {
+		rule.Query = nil
+	}
+	if err := validateWindow(rule); err != nil {
+		return rule, err
+	}
+	if rule.Device == "" && rule.Language == "" && rule.Query == nil && rule.From == "" {
+		return rule, model.NewRequestError(model.ErrInvalidRules, "rule must have at least one condition")
+	}
+
+	originalURL, err := s.normalizeURL(rule.URL)
+	if err != nil {
+		return rule, err
+	}
+	if err := s.CheckTarget(ctx, originalURL); err != nil {
+		return rule, err
+	}
+	rule.URL = originalURL
+	return rule, nil
+}
+
+// validateWindow проверяет время суток правила: from и to в формате HH:MM задаются вместе,
+// timezone это имя зоны IANA, по умолчанию UTC
+func validateWindow(rule model.Rule) error {
+	if rule.From == "" && rule.To == "" {
+		if rule.Timezone != "" {
+			return model.NewRequestError(model.ErrInvalidRules, "timezone requires from and to")
+		}
+		return nil
+	}
+	from, errFrom := time.Parse(model.RuleTimeLayout, rule.From)
+	to, errTo := time.Parse(model.RuleTimeLayout, rule.To)
+	if errFrom != nil || errTo != nil {
+		return model.NewRequestError(model.ErrInvalidRules, "from and to must both be set in HH:MM format")
+	}
+	if from.Equal(to) {
+		return model.NewRequestError(model.ErrInvalidRules, "from and to must differ")
+	}
+	if _, err := time.LoadLocation(rule.Timezone); err != nil {
+		return model.NewRequestError(model.ErrInvalidRules, fmt.Sprintf("unknown timezone %q", rule.Timezone))
+	}
+	return nil
+}
+
+// Rules метод сервисного слоя, правила перенаправления ссылки для её владельца
+func (s *URLService) Rules(ctx context.Context, userID, shortCode string) ([]model.Rule, error) {
+	const op = "URLService.Rules"
+	log := s.logger.With(
+		slog.String("op", op),
+	)
+
+	url, err := s.repo.Get(ctx, shortCode)
+	if errors.Is(err, model.ErrURLNotFound) || err == nil && (url.UserID != userID || url.DeletedFlag) {
+		return nil, fmt.Errorf("%s: %w", op, model.ErrURLNotFound)
+	}
+	if err != nil {
+		log.Error(op, "error", err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if url.Rules == nil {
+		return []model.Rule{}, nil
+	}
+	return url.Rules, nil
+}
+
+// UpdateRules метод сервисного слоя, замена правил перенаправления ссылки её владельцем
+func (s *URLService) UpdateRules(ctx context.Context, userID, shortCode string, rules []model.Rule) (*model.URL, error) {
+	const op = "URLService.UpdateRules"
+	log := s.logger.With(
+		slog.String("op", op),
+	)
+
+	rules, err := s.normalizeRules(ctx, rules)
+	if err != nil {
+		return nil, err
+	}
+	edit := model.URLRulesEdit{UUID: shortCode, UserID: userID, Rules: rules}
+	if err := s.repo.UpdateRules(ctx, edit); err != nil {
+		if !errors.Is(err, model.ErrURLNotFound) {
+			log.Error(op, "error", err)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	url, err := s.repo.Get(ctx, shortCode)
+	if err != nil {
+		log.Error(op, "error", err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return url, nil
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLService_NormalizeRules(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := NewURLService(&mockURLRepo{}, &model.ShortServiceConfig{BaseURL: "http://localhost:8080"}, &mockShortener{}, logger)
+
+	tests := []struct {
+		name    string
+		rule    model.Rule
+		want    model.Rule
+		wantErr error
+	}{
+		{
+			name: "device and language",
+			rule: model.Rule{URL: "HTTPS://Apps.Apple.com/app/id1", Device: " iOS ", Language: "PT-BR"},
+			want: model.Rule{URL: "https://apps.apple.com/app/id1", Device: "ios", Language: "pt-br"},
+		},
+		{
+			name: "query and window",
+			rule: model.Rule{URL: "https://example.com", Query: map[string]string{"ref": "promo"}, From: "22:00", To: "06:00", Timezone: "UTC"},
+			want: model.Rule{URL: "https://example.com/", Query: map[string]string{"ref": "promo"}, From: "22:00", To: "06:00", Timezone: "UTC"},
+		},
+		{name: "no condition", rule: model.Rule{URL: "https://example.com"}, wantErr: model.ErrInvalidRules},
+		{name: "unknown device", rule: model.Rule{URL: "https://example.com", Device: "fridge"}, wantErr: model.ErrInvalidRules},
+		{name: "bad language", rule: model.Rule{URL: "https://example.com", Language: "english"}, wantErr: model.ErrInvalidRules},
+		{name: "from without to", rule: model.Rule{URL: "https://example.com", From: "09:00"}, wantErr: model.ErrInvalidRules},
+		{name: "bad time", rule: model.Rule{URL: "https://example.com", From: "9am", To: "18:00"}, wantErr: model.ErrInvalidRules},
+		{name: "unknown timezone", rule: model.Rule{URL: "https://example.com", From: "09:00", To: "18:00", Timezone: "Nowhere/Nothing"}, wantErr: model.ErrInvalidRules},
+		{name: "timezone without window", rule: model.Rule{URL: "https://example.com", Device: "ios", Timezone: "UTC"}, wantErr: model.ErrInvalidRules},
+		{name: "invalid url", rule: model.Rule{URL: "ftp://example.com", Device: "ios"}, wantErr: model.ErrInvalidURL},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := svc.normalizeRules(context.Background(), []model.Rule{test.rule})
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []model.Rule{test.want}, got)
+		})
+	}
+
+	_, err := svc.normalizeRules(context.Background(), make([]model.Rule, maxRules+1))
+	assert.ErrorIs(t, err, model.ErrInvalidRules)
+}
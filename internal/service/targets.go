@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+const (
+	minTargets = 2
+	maxTargets = 10
+	maxWeight  = 10000
+)
+
+// variantNamePattern допустимое имя варианта, оно попадает в cookie и в аудит
+var variantNamePattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// normalizeTargets проверяет варианты A/B распределения: адреса проходят ту же нормализацию и проверки,
+// что и при сокращении, веса положительны, имена уникальны. Вариант без имени получает букву по порядку
+func (s *URLService) normalizeTargets(ctx context.Context, targets []model.Target) ([]model.Target, error) {
+	if len(targets) == 0 {
+		return nil, nil
+	}
+	if len(targets) < minTargets || len(targets) > maxTargets {
+		return nil, model.NewRequestError(model.ErrInvalidTargets,
+			fmt.Sprintf("targets must list %d to %d variants", minTargets, maxTargets))
+	}
+	normalized := make([]model.Target, 0, len(targets))
+	names := make(map[string]bool, len(targets))
+	for i, target := range targets {
+		if target.Name == "" {
+			target.Name = string(rune('a' + i))
+		}
+		if !variantNamePattern.MatchString(target.Name) {
+			return nil, model.NewRequestError(model.ErrInvalidTargets,
+				fmt.Sprintf("variant name must match %s", variantNamePattern.String()))
+		}
+		if names[target.Name] {
+			return nil, model.NewRequestError(model.ErrInvalidTargets, fmt.Sprintf("variant %q is listed twice", target.Name))
+		}
+		names[target.Name] = true
+		if target.Weight < 1 || target.Weight > maxWeight {
+			return nil, model.NewRequestError(model.ErrInvalidTargets,
+				fmt.Sprintf("variant %q weight must be between 1 and %d", target.Name, maxWeight))
+		}
+		originalURL, err := s.normalizeURL(target.URL)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.CheckTarget(ctx, originalURL); err != nil {
+			return nil, err
+		}
+		target.URL = originalURL
+		normalized = append(normalized, target)
+	}
+	return normalized, nil
+}
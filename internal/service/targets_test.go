@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLService_SaveTargets(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &model.ShortServiceConfig{BaseURL: "http://localhost:8080"}
+	svc := NewURLService(&mockURLRepo{}, cfg, &mockShortener{}, logger)
+
+	split := []model.Target{
+		{URL: "HTTPS://Example.com/a", Weight: 70},
+		{Name: "landing-b", URL: "https://example.com/b", Weight: 30},
+	}
+
+	tests := []struct {
+		name    string
+		url     string
+		targets []model.Target
+		want    []model.Target
+		wantErr error
+	}{
+		{
+			name:    "url taken from first target",
+			targets: split,
+			want: []model.Target{
+				{Name: "a", URL: "https://example.com/a", Weight: 70},
+				{Name: "landing-b", URL: "https://example.com/b", Weight: 30},
+			},
+		},
+		{
+			name:    "url matches first target",
+			url:     "https://example.com/a",
+			targets: split,
+			want: []model.Target{
+				{Name: "a", URL: "https://example.com/a", Weight: 70},
+				{Name: "landing-b", URL: "https://example.com/b", Weight: 30},
+			},
+		},
+		{name: "url differs from first target", url: "https://example.com/b", targets: split, wantErr: model.ErrInvalidTargets},
+		{name: "single target", targets: split[:1], wantErr: model.ErrInvalidTargets},
+		{name: "zero weight", targets: []model.Target{split[0], {URL: "https://example.com/b"}}, wantErr: model.ErrInvalidTargets},
+		{name: "duplicate name", targets: []model.Target{{Name: "x", URL: "https://a.com", Weight: 1}, {Name: "x", URL: "https://b.com", Weight: 1}}, wantErr: model.ErrInvalidTargets},
+		{name: "bad name", targets: []model.Target{{Name: "Variant A", URL: "https://a.com", Weight: 1}, {URL: "https://b.com", Weight: 1}}, wantErr: model.ErrInvalidTargets},
+		{name: "invalid target url", targets: []model.Target{split[0], {URL: "ftp://example.com", Weight: 1}}, wantErr: model.ErrInvalidURL},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			url, err := svc.save(context.Background(), test.url, model.ShortenOptions{Targets: test.targets})
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, url.Targets)
+			assert.Equal(t, test.want[0].URL, url.OriginalURL)
+			assert.True(t, url.ForceNew, "split links are never shared")
+		})
+	}
+}
@@ -1,16 +1,23 @@
 package service
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"regexp"
+	"slices"
+	"sync/atomic"
 	"time"
 
+	"github.com/ArtShib/urlshortener/internal/lib/ratelimit"
 	"github.com/ArtShib/urlshortener/internal/model"
 )
 
 const (
-	longOperationTimeout = 10 * time.Second
+	longOperationTimeout    = 10 * time.Second
+	defaultDeletedRetention = 7 * 24 * time.Hour
 )
 
 // URLRepository описывает интерфейс для работы с репозиторием данных urlshort
@@ -18,57 +25,162 @@ type URLRepository interface {
 	Save(ctx context.Context, url *model.URL) (*model.URL, error)
 	Get(ctx context.Context, shortCode string) (*model.URL, error)
 	Ping(ctx context.Context) error
-	GetBatch(ctx context.Context, userID string) (model.URLUserBatch, error)
+	Stats(ctx context.Context) (*model.RepositoryStats, error)
+	GetBatch(ctx context.Context, filter model.URLUserFilter) (model.URLUserBatch, error)
+	Export(ctx context.Context, userID string, yield func(model.URLExport) error) error
 	DeleteBatch(ctx context.Context, deleteRequest model.URLUserRequestArray) error
+	RestoreBatch(ctx context.Context, restoreRequest model.URLUserRequestArray, deletedAfter time.Time) error
+	MarkExpired(ctx context.Context, now time.Time, limit int) (int, error)
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time, limit int) (int, error)
+	ConsumeClick(ctx context.Context, uuid string) (bool, error)
+	UpdateURL(ctx context.Context, edit model.URLEdit) error
+	UpdateRules(ctx context.Context, edit model.URLRulesEdit) error
+	History(ctx context.Context, uuid string) ([]model.URLHistoryEntry, error)
+	SaveClicks(ctx context.Context, clicks []model.Click) error
+	ClickStats(ctx context.Context, uuid string, granularity string, from, to time.Time) (*model.ClickStats, error)
 }
 
 // Shortener описывает интерфейс для генерации uuid и ShortURL
 type Shortener interface {
-	GenerateUUID() (string, error)
+	GenerateUUID(ctx context.Context, originalURL string, length int, attempt int) (string, error)
 	GenerateShortURL(url string, uuid string) string
+	Strategy() string
+}
+
+// URLChecker проверка url перед сохранением и переходом.
+// Отказ возвращается как model.RequestError с model.ErrURLBlocked и причиной
+type URLChecker interface {
+	Check(ctx context.Context, originalURL string) error
 }
 
 // URLService структура URLService
 type URLService struct {
-	repo      URLRepository
-	config    *model.ShortServiceConfig
-	shortener Shortener
-	logger    *slog.Logger
+	repo         URLRepository
+	config       *model.ShortServiceConfig
+	shortener    Shortener
+	aliasPattern *regexp.Regexp
+	attempts     *ratelimit.Limiter
+	codeLength   atomic.Int32
+	reserved     []string
+	checkers     []URLChecker
+	logger       *slog.Logger
 }
 
 // NewURLService конструктор для URLService
-func NewURLService(repo URLRepository, cfg *model.ShortServiceConfig, shortener Shortener, logger *slog.Logger) *URLService {
+func NewURLService(repo URLRepository, cfg *model.ShortServiceConfig, shortener Shortener, logger *slog.Logger, checkers ...URLChecker) *URLService {
+	const op = "URLService.NewURLService"
+	aliasPattern, err := compileAliasPattern(cfg.AliasPattern)
+	if err != nil {
+		logger.Error(op, "error", fmt.Errorf("alias pattern %q: %w, using default", cfg.AliasPattern, err))
+		aliasPattern = regexp.MustCompile(defaultAliasPattern)
+	}
 	urlService := &URLService{
-		repo:      repo,
-		config:    cfg,
-		shortener: shortener,
-		logger:    logger,
+		repo:         repo,
+		config:       cfg,
+		shortener:    shortener,
+		aliasPattern: aliasPattern,
+		attempts:     ratelimit.New(cfg.PasswordMaxAttempts, cfg.PasswordAttemptWindow),
+		reserved:     slices.Clone(cfg.ReservedAliases),
+		checkers:     append([]URLChecker{newSelfLinkChecker(cfg.BaseURL)}, checkers...),
+		logger:       logger,
 	}
+	urlService.codeLength.Store(int32(cmp.Or(cfg.CodeLength, defaultCodeLength)))
 	return urlService
 }
 
 // Shorten метод сервисного слоя, сокращения url
-func (s *URLService) Shorten(ctx context.Context, url string) (string, error) {
+func (s *URLService) Shorten(ctx context.Context, req model.RequestShortener) (string, error) {
 	const op = "URLService.Shorten"
 	log := s.logger.With(
 		slog.String("op", op),
 	)
-	if url == "" {
-		log.Error(op, "error", fmt.Errorf("empty URL"))
-		return "", fmt.Errorf("%s: %w", op, fmt.Errorf("empty URL"))
-	}
+	ctx, cancel := context.WithTimeout(ctx, longOperationTimeout)
+	defer cancel()
 
-	uuid, err := s.shortener.GenerateUUID()
-	if err != nil {
+	urlModel, err := s.save(ctx, req.URL, req.ShortenOptions)
+	if urlModel == nil {
 		log.Error(op, "error", err)
 		return "", fmt.Errorf("%s: %w", op, err)
 	}
-	shortURL := s.config.BaseURL
+	if err != nil {
+		log.Error(op, "error", err)
+	}
+	return urlModel.ShortURL, err
+}
+
+// save генерирует короткий код (или берёт alias) и сохраняет url от имени пользователя из контекста.
+// При конфликте возвращает уже существующую ссылку пользователя вместе с model.ErrURLConflict
+func (s *URLService) save(ctx context.Context, originalURL string, opts model.ShortenOptions) (*model.URL, error) {
+	targets, err := s.normalizeTargets(ctx, opts.Targets)
+	if err != nil {
+		return nil, err
+	}
+	if targets != nil {
+		// адрес ссылки с A/B распределением это её первый вариант
+		if originalURL != "" {
+			if normalized, err := s.normalizeURL(originalURL); err != nil || normalized != targets[0].URL {
+				return nil, model.NewRequestError(model.ErrInvalidTargets, "url must be omitted or match the first target")
+			}
+		}
+		originalURL = targets[0].URL
+		// у каждой ссылки с вариантами собственная статистика, переиспользовать чужую нельзя
+		opts.ForceNew = true
+	} else {
+		if originalURL, err = s.normalizeURL(originalURL); err != nil {
+			return nil, err
+		}
+		if err := s.CheckTarget(ctx, originalURL); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Alias != "" {
+		if err := s.validateAlias(opts.Alias); err != nil {
+			return nil, err
+		}
+		// alias всегда создаёт новую ссылку, иначе пользователь получил бы чужой код
+		opts.ForceNew = true
+	}
+
+	expiresAt, err := expiryTime(opts, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if opts.MaxClicks < 0 {
+		return nil, model.NewRequestError(model.ErrInvalidMaxClicks, "max_clicks must not be negative")
+	}
+	if opts.MaxClicks > 0 {
+		// у ссылки с лимитом переходов собственный счётчик, переиспользовать чужую нельзя
+		opts.ForceNew = true
+	}
+	passwordHash, err := hashPassword(opts.Password)
+	if err != nil {
+		return nil, err
+	}
+	if passwordHash != "" {
+		// защищённая ссылка не должна совпасть с открытой ссылкой на тот же url
+		opts.ForceNew = true
+	}
+	if opts.Preview || opts.Passthrough || opts.UTM != nil {
+		// предпросмотр и параметры перехода не должны появиться у чужой ссылки на тот же url
+		opts.ForceNew = true
+	}
 
 	urlModel := &model.URL{
-		UUID:        uuid,
-		ShortURL:    s.shortener.GenerateShortURL(shortURL, uuid),
-		OriginalURL: url,
+		OriginalURL:     originalURL,
+		CreatedAt:       time.Now().UTC().Truncate(time.Microsecond),
+		ForceNew:        opts.ForceNew,
+		ExpiresAt:       expiresAt,
+		MaxClicks:       opts.MaxClicks,
+		PasswordHash:    passwordHash,
+		PreviewRequired: opts.Preview,
+		Targets:         targets,
+	}
+
+	if err := applyMetadata(urlModel, opts); err != nil {
+		return nil, err
+	}
+	if err := applyRedirect(urlModel, opts); err != nil {
+		return nil, err
 	}
 
 	userID, ok := ctx.Value(model.UserIDKey).(string)
@@ -76,14 +188,34 @@ func (s *URLService) Shorten(ctx context.Context, url string) (string, error) {
 		urlModel.UserID = userID
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, longOperationTimeout)
-	defer cancel()
+	if opts.Alias != "" {
+		urlModel.UUID = opts.Alias
+		urlModel.ShortURL = s.shortener.GenerateShortURL(s.config.BaseURL, opts.Alias)
+		return s.repo.Save(ctx, urlModel)
+	}
+	return s.saveGenerated(ctx, urlModel)
+}
 
-	urlModel, err = s.repo.Save(ctx, urlModel)
-	if err != nil {
-		log.Error(op, "error", err)
+// expiryTime вычисляет момент истечения ссылки из expires_at или ttl
+func expiryTime(opts model.ShortenOptions, now time.Time) (*time.Time, error) {
+	switch {
+	case opts.ExpiresAt != nil && opts.TTL != "":
+		return nil, model.NewRequestError(model.ErrInvalidExpiry, "specify either expires_at or ttl")
+	case opts.TTL != "":
+		ttl, err := time.ParseDuration(opts.TTL)
+		if err != nil || ttl <= 0 {
+			return nil, model.NewRequestError(model.ErrInvalidExpiry, "ttl must be a positive duration like 24h")
+		}
+		expiresAt := now.Add(ttl).UTC()
+		return &expiresAt, nil
+	case opts.ExpiresAt != nil:
+		if !opts.ExpiresAt.After(now) {
+			return nil, model.NewRequestError(model.ErrInvalidExpiry, "expires_at must be in the future")
+		}
+		expiresAt := opts.ExpiresAt.UTC()
+		return &expiresAt, nil
 	}
-	return urlModel.ShortURL, err
+	return nil, nil
 }
 
 // GetID метод сервисного слоя, получения оригинального url
@@ -107,35 +239,35 @@ func (s *URLService) GetID(ctx context.Context, shortCode string) (*model.URL, e
 	return url, nil
 }
 
+// ConsumeClick метод сервисного слоя, учёт перехода по ссылке с лимитом переходов
+func (s *URLService) ConsumeClick(ctx context.Context, shortCode string) (bool, error) {
+	const op = "URLService.ConsumeClick"
+	log := s.logger.With(
+		slog.String("op", op),
+	)
+	ok, err := s.repo.ConsumeClick(ctx, shortCode)
+	if err != nil {
+		log.Error(op, "error", err)
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return ok, nil
+}
+
 // ShortenJSON метод сервисного слоя, сокращение url. На вход подается json
-func (s *URLService) ShortenJSON(ctx context.Context, url string) (*model.ResponseShortener, error) {
+func (s *URLService) ShortenJSON(ctx context.Context, req model.RequestShortener) (*model.ResponseShortener, error) {
 	const op = "URLService.ShortenJSON"
 	log := s.logger.With(
 		slog.String("op", op),
 	)
 
-	if url == "" {
-		log.Error(op, "error", fmt.Errorf("empty URL"))
-		return nil, fmt.Errorf("%s: %w", op, fmt.Errorf("empty URL"))
-	}
-
-	uuid, err := s.shortener.GenerateUUID()
-	if err != nil {
+	urlModel, err := s.save(ctx, req.URL, req.ShortenOptions)
+	if urlModel == nil {
 		log.Error(op, "error", err)
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-
-	shortURL := s.config.BaseURL
-	urlModel := &model.URL{
-		UUID:        uuid,
-		ShortURL:    s.shortener.GenerateShortURL(shortURL, uuid),
-		OriginalURL: url,
-	}
-
-	urlModel, err = s.repo.Save(ctx, urlModel)
 	if err != nil {
 		log.Error(op, "error", err)
-		return nil, fmt.Errorf("%s: %w", op, err)
+		err = fmt.Errorf("%s: %w", op, err)
 	}
 
 	return &model.ResponseShortener{
@@ -148,6 +280,20 @@ func (s *URLService) Ping(ctx context.Context) error {
 	return s.repo.Ping(ctx)
 }
 
+// Stats метод сервисного слоя, получение состояния репозитория
+func (s *URLService) Stats(ctx context.Context) (*model.RepositoryStats, error) {
+	const op = "URLService.Stats"
+	log := s.logger.With(
+		slog.String("op", op),
+	)
+	stats, err := s.repo.Stats(ctx)
+	if err != nil {
+		log.Error(op, "error", err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return stats, nil
+}
+
 // ShortenJSONBatch метод сервисного слоя сокращение url пачками
 func (s *URLService) ShortenJSONBatch(ctx context.Context, urls model.RequestShortenerBatchArray) (model.ResponseShortenerBatchArray, error) {
 	const op = "URLService.ShortenJSONBatch"
@@ -155,45 +301,43 @@ func (s *URLService) ShortenJSONBatch(ctx context.Context, urls model.RequestSho
 		slog.String("op", op),
 	)
 
-	var shortenerBatch model.ResponseShortenerBatchArray
-
-	for _, url := range urls {
-		uuid, err := s.shortener.GenerateUUID()
-		if err != nil {
-			log.Error(op, "error", err)
-			return nil, fmt.Errorf("%s: %w", op, err)
-		}
-		shortURL := s.config.BaseURL
-		urlModel := &model.URL{
-			UUID:        uuid,
-			ShortURL:    s.shortener.GenerateShortURL(shortURL, uuid),
-			OriginalURL: url.OriginalURL,
+	var (
+		shortenerBatch model.ResponseShortenerBatchArray
+		batchErr       error
+	)
+	s.saveBatch(ctx, urls, func(i int, urlModel *model.URL, err error) bool {
+		var reqErr *model.RequestError
+		if errors.As(err, &reqErr) {
+			err = model.NewRequestError(reqErr.Err, fmt.Sprintf("correlation_id %q: %s", urls[i].CorrelationID, reqErr.Reason))
 		}
-
-		if _, err := s.repo.Save(ctx, urlModel); err != nil {
-			log.Error(op, "error", err)
-			return nil, fmt.Errorf("%s: %w", op, err)
+		if urlModel == nil || err != nil && !errors.Is(err, model.ErrURLConflict) {
+			if err != nil {
+				batchErr = &model.BatchItemError{CorrelationID: urls[i].CorrelationID, OriginalURL: urls[i].OriginalURL, Err: err}
+			}
+			return false
 		}
 		shortenerBatch = append(shortenerBatch, model.ResponseShortenerBatch{
-			CorrelationID: url.CorrelationID,
+			CorrelationID: urls[i].CorrelationID,
 			ShortURL:      urlModel.ShortURL,
 		})
+		return true
+	})
+	if batchErr != nil {
+		log.Error(op, "error", batchErr)
+		return nil, fmt.Errorf("%s: %w", op, batchErr)
 	}
 	return shortenerBatch, nil
 }
 
-// GetJSONBatch метод сервисного слоя, получения оригинального url по id пользователя
-func (s *URLService) GetJSONBatch(ctx context.Context, userID string) (model.URLUserBatch, error) {
-	const op = "URLService.GetJSONBatch"
-	log := s.logger.With(
-		slog.String("op", op),
-	)
-	UURLUserBatch, err := s.repo.GetBatch(ctx, userID)
-	if err != nil {
-		log.Error(op, "error", err)
-		return nil, fmt.Errorf("%s: %w", op, err)
+// saveBatch сохраняет url пачки по порядку и передаёт результат каждого в yield.
+// Сохранение прекращается, если yield вернул false
+func (s *URLService) saveBatch(ctx context.Context, urls model.RequestShortenerBatchArray, yield func(i int, url *model.URL, err error) bool) {
+	for i, url := range urls {
+		urlModel, err := s.save(ctx, url.OriginalURL, url.ShortenOptions)
+		if !yield(i, urlModel, err) {
+			return
+		}
 	}
-	return UURLUserBatch, nil
 }
 
 // DeleteBatch метод сервисного слоя, удаления записи (соотношения uuid ( - оригинального url) из репозитория
@@ -208,3 +352,46 @@ func (s *URLService) DeleteBatch(ctx context.Context, batch model.URLUserRequest
 	}
 	return nil
 }
+
+// RestoreBatch метод сервисного слоя, восстановление удалённых ссылок в пределах срока хранения удалённых
+func (s *URLService) RestoreBatch(ctx context.Context, batch model.URLUserRequestArray) error {
+	const op = "URLService.RestoreBatch"
+	log := s.logger.With(
+		slog.String("op", op),
+	)
+	deletedAfter := time.Now().Add(-cmp.Or(s.config.DeletedRetention, defaultDeletedRetention))
+	if err := s.repo.RestoreBatch(ctx, batch, deletedAfter); err != nil {
+		log.Error(op, "error", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// MarkExpired метод сервисного слоя, пометка ссылок с истёкшим сроком жизни
+func (s *URLService) MarkExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	const op = "URLService.MarkExpired"
+	log := s.logger.With(
+		slog.String("op", op),
+	)
+	count, err := s.repo.MarkExpired(ctx, now, limit)
+	if err != nil {
+		log.Error(op, "error", err)
+		return count, fmt.Errorf("%s: %w", op, err)
+	}
+	return count, nil
+}
+
+// PurgeDeleted метод сервисного слоя, окончательное удаление ссылок, срок хранения удалённых которых истёк
+func (s *URLService) PurgeDeleted(ctx context.Context, now time.Time, limit int) (int, error) {
+	const op = "URLService.PurgeDeleted"
+	log := s.logger.With(
+		slog.String("op", op),
+	)
+	deletedBefore := now.Add(-cmp.Or(s.config.DeletedRetention, defaultDeletedRetention))
+	count, err := s.repo.PurgeDeleted(ctx, deletedBefore, limit)
+	if err != nil {
+		log.Error(op, "error", err)
+		return count, fmt.Errorf("%s: %w", op, err)
+	}
+	return count, nil
+}
@@ -6,7 +6,9 @@ import (
 	"log/slog"
 	"runtime"
 	"testing"
+	"time"
 
+	"github.com/ArtShib/urlshortener/internal/lib/shortener"
 	"github.com/ArtShib/urlshortener/internal/model"
 )
 
@@ -20,22 +22,58 @@ func (m *mockURLRepo) Get(ctx context.Context, shortCode string) (*model.URL, er
 	return &model.URL{OriginalURL: "http://yandex.ru", ShortURL: shortCode}, nil
 }
 func (m *mockURLRepo) Ping(ctx context.Context) error { return nil }
-func (m *mockURLRepo) GetBatch(ctx context.Context, userID string) (model.URLUserBatch, error) {
+func (m *mockURLRepo) Stats(ctx context.Context) (*model.RepositoryStats, error) {
+	return &model.RepositoryStats{}, nil
+}
+func (m *mockURLRepo) GetBatch(ctx context.Context, filter model.URLUserFilter) (model.URLUserBatch, error) {
 	return nil, nil
 }
+func (m *mockURLRepo) Export(ctx context.Context, userID string, yield func(model.URLExport) error) error {
+	return nil
+}
 func (m *mockURLRepo) DeleteBatch(ctx context.Context, deleteRequest model.URLUserRequestArray) error {
 	return nil
 }
+func (m *mockURLRepo) RestoreBatch(ctx context.Context, restoreRequest model.URLUserRequestArray, deletedAfter time.Time) error {
+	return nil
+}
+func (m *mockURLRepo) PurgeDeleted(ctx context.Context, deletedBefore time.Time, limit int) (int, error) {
+	return 0, nil
+}
+func (m *mockURLRepo) MarkExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	return 0, nil
+}
+func (m *mockURLRepo) ConsumeClick(ctx context.Context, uuid string) (bool, error) {
+	return true, nil
+}
+func (m *mockURLRepo) UpdateURL(ctx context.Context, edit model.URLEdit) error {
+	return nil
+}
+func (m *mockURLRepo) UpdateRules(ctx context.Context, edit model.URLRulesEdit) error {
+	return nil
+}
+func (m *mockURLRepo) History(ctx context.Context, uuid string) ([]model.URLHistoryEntry, error) {
+	return nil, nil
+}
+func (m *mockURLRepo) SaveClicks(ctx context.Context, clicks []model.Click) error {
+	return nil
+}
+func (m *mockURLRepo) ClickStats(ctx context.Context, uuid string, granularity string, from, to time.Time) (*model.ClickStats, error) {
+	return &model.ClickStats{}, nil
+}
 
 type mockShortener struct{}
 
-func (m *mockShortener) GenerateUUID() (string, error) {
+func (m *mockShortener) GenerateUUID(ctx context.Context, originalURL string, length int, attempt int) (string, error) {
 	// Возвращаем статику, чтобы не бенчмаркать генератор UUID (если это не цель)
 	return "a7v4M9PY", nil
 }
 func (m *mockShortener) GenerateShortURL(url string, uuid string) string {
 	return url + "/" + uuid
 }
+func (m *mockShortener) Strategy() string {
+	return shortener.StrategyRandom
+}
 
 func BenchmarkURLService_Shorten(b *testing.B) {
 
@@ -53,7 +91,7 @@ func BenchmarkURLService_Shorten(b *testing.B) {
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		_, _ = svc.Shorten(ctx, originalURL)
+		_, _ = svc.Shorten(ctx, model.RequestShortener{URL: originalURL})
 	}
 }
 
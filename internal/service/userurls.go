@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+const (
+	defaultPageLimit = 100
+	maxPageLimit     = 1000
+)
+
+// GetJSONBatch метод сервисного слоя, получения страницы ссылок пользователя.
+// Ссылки упорядочены по (created_at, uuid), курсор следующей страницы пуст на последней странице.
+// Без limit и cursor возвращаются все ссылки одной страницей
+func (s *URLService) GetJSONBatch(ctx context.Context, filter model.URLUserFilter) (*model.URLUserPage, error) {
+	const op = "URLService.GetJSONBatch"
+	log := s.logger.With(
+		slog.String("op", op),
+	)
+
+	limit, err := validateUserFilter(&filter)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 {
+		// на одну ссылку больше, чтобы понять, есть ли следующая страница
+		filter.Limit = limit + 1
+	}
+	urls, err := s.repo.GetBatch(ctx, filter)
+	if err != nil {
+		log.Error(op, "error", err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	page := &model.URLUserPage{URLs: urls}
+	if page.URLs == nil {
+		page.URLs = model.URLUserBatch{}
+	}
+	if limit > 0 && len(urls) > limit {
+		page.URLs = urls[:limit]
+		last := urls[limit-1]
+		page.NextCursor = model.URLUserCursor{CreatedAt: last.CreatedAt, UUID: last.UUID}.String()
+	}
+	return page, nil
+}
+
+// validateUserFilter проверяет фильтр, подставляет значения по умолчанию и возвращает размер страницы.
+// Ноль означает выборку без постраничной разбивки: клиент не передал ни limit, ни cursor
+func validateUserFilter(filter *model.URLUserFilter) (int, error) {
+	switch filter.Status {
+	case "":
+		filter.Status = model.URLStatusAll
+	case model.URLStatusAll, model.URLStatusActive, model.URLStatusDeleted:
+	default:
+		return 0, model.NewRequestError(model.ErrInvalidFilter, "status must be all, active or deleted")
+	}
+	switch filter.Sort {
+	case "":
+		filter.Sort = model.SortCreatedDesc
+	case model.SortCreatedDesc, model.SortCreatedAsc:
+	default:
+		return 0, model.NewRequestError(model.ErrInvalidFilter, "sort must be created_desc or created_asc")
+	}
+	tags, err := normalizeTags(filter.Tags)
+	if err != nil {
+		return 0, err
+	}
+	filter.Tags = tags
+	switch {
+	case filter.Limit == 0 && filter.Cursor == nil:
+		return 0, nil
+	case filter.Limit == 0:
+		return defaultPageLimit, nil
+	case filter.Limit < 0 || filter.Limit > maxPageLimit:
+		return 0, model.NewRequestError(model.ErrInvalidFilter, fmt.Sprintf("limit must be between 1 and %d", maxPageLimit))
+	}
+	return filter.Limit, nil
+}
+
+// ExportURLs метод сервисного слоя, выгрузка всех ссылок пользователя, включая удалённые.
+// Ссылки передаются в yield по одной, ошибка yield прерывает выгрузку
+func (s *URLService) ExportURLs(ctx context.Context, userID string, yield func(model.URLExport) error) error {
+	const op = "URLService.ExportURLs"
+	log := s.logger.With(
+		slog.String("op", op),
+	)
+
+	if err := s.repo.Export(ctx, userID, yield); err != nil {
+		log.Error(op, "error", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateUserFilter(t *testing.T) {
+	tests := []struct {
+		name      string
+		filter    model.URLUserFilter
+		wantLimit int
+		wantErr   bool
+	}{
+		{name: "defaults", filter: model.URLUserFilter{}, wantLimit: 0},
+		{name: "cursor without limit", filter: model.URLUserFilter{Cursor: &model.URLUserCursor{UUID: "abc"}}, wantLimit: defaultPageLimit},
+		{name: "explicit", filter: model.URLUserFilter{Limit: 10, Status: model.URLStatusDeleted, Sort: model.SortCreatedAsc}, wantLimit: 10},
+		{name: "limit too large", filter: model.URLUserFilter{Limit: maxPageLimit + 1}, wantErr: true},
+		{name: "negative limit", filter: model.URLUserFilter{Limit: -1}, wantErr: true},
+		{name: "unknown status", filter: model.URLUserFilter{Status: "archived"}, wantErr: true},
+		{name: "unknown sort", filter: model.URLUserFilter{Sort: "original_url"}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			limit, err := validateUserFilter(&test.filter)
+			if test.wantErr {
+				assert.ErrorIs(t, err, model.ErrInvalidFilter)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.wantLimit, limit)
+			assert.NotEmpty(t, test.filter.Status)
+			assert.NotEmpty(t, test.filter.Sort)
+		})
+	}
+}
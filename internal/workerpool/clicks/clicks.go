@@ -0,0 +1,121 @@
+package clicks
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// URLService описывает интерфейс сохранения переходов.
+type URLService interface {
+	SaveClicks(ctx context.Context, clicks []model.Click) error
+}
+
+// Recorder структура буферизованной записи переходов. Переходы копятся в канале
+// и сохраняются пачками по размеру или по интервалу, чтобы не тормозить редирект
+type Recorder struct {
+	logger     *slog.Logger
+	wg         sync.WaitGroup
+	cancel     context.CancelFunc
+	clickCh    chan model.Click
+	dropped    atomic.Int64
+	URLService URLService
+	config     *model.WorkerPoolClicks
+}
+
+// New конструктор Recorder
+func New(svc URLService, log *slog.Logger, cfg *model.WorkerPoolClicks) *Recorder {
+	return &Recorder{
+		logger:     log,
+		clickCh:    make(chan model.Click, cfg.BufferSize),
+		URLService: svc,
+		config:     cfg,
+	}
+}
+
+// Start запускает Recorder
+func (r *Recorder) Start(ctx context.Context) {
+	const op = "Recorder.Start"
+	log := r.logger.With(
+		slog.String("op", op),
+	)
+	log.Debug("Starting Recorder")
+	ctx, cancel := context.WithCancel(ctx)
+	r.cancel = cancel
+
+	r.wg.Add(1)
+	go r.run(ctx)
+}
+
+// Stop останавливает Recorder и сохраняет накопленные переходы
+func (r *Recorder) Stop() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+}
+
+// AddClick добавляет переход в очередь. При заполненной очереди переход отбрасывается
+func (r *Recorder) AddClick(click model.Click) {
+	select {
+	case r.clickCh <- click:
+	default:
+		// пишем в лог только на степенях двойки, чтобы не засорять его под нагрузкой
+		if dropped := r.dropped.Add(1); dropped&(dropped-1) == 0 {
+			r.logger.Warn("click queue full, dropping clicks", "dropped", dropped)
+		}
+	}
+}
+
+func (r *Recorder) run(ctx context.Context) {
+	defer r.wg.Done()
+
+	batchSize := max(r.config.BatchSize, 1)
+	batch := make([]model.Click, 0, batchSize)
+	interval := r.config.FlushInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			for {
+				select {
+				case click := <-r.clickCh:
+					batch = append(batch, click)
+				default:
+					r.flush(batch)
+					return
+				}
+			}
+		case click := <-r.clickCh:
+			batch = append(batch, click)
+			if len(batch) >= batchSize {
+				batch = r.flush(batch)
+			}
+		case <-ticker.C:
+			batch = r.flush(batch)
+		}
+	}
+}
+
+// flush сохраняет пачку и возвращает пустой буфер для следующей
+func (r *Recorder) flush(batch []model.Click) []model.Click {
+	const op = "Recorder.flush"
+	if len(batch) == 0 {
+		return batch
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := r.URLService.SaveClicks(ctx, batch); err != nil {
+		r.logger.Error(op, "error", err, "clicks", len(batch))
+	}
+	return batch[:0]
+}
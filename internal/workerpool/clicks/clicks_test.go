@@ -0,0 +1,54 @@
+package clicks
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingService struct {
+	mu      sync.Mutex
+	batches [][]model.Click
+}
+
+func (s *recordingService) SaveClicks(ctx context.Context, clicks []model.Click) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, append([]model.Click(nil), clicks...))
+	return nil
+}
+
+func (s *recordingService) sizes() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sizes := make([]int, 0, len(s.batches))
+	for _, batch := range s.batches {
+		sizes = append(sizes, len(batch))
+	}
+	return sizes
+}
+
+func TestRecorder(t *testing.T) {
+	svc := &recordingService{}
+	recorder := New(svc, slog.New(slog.NewTextHandler(io.Discard, nil)), &model.WorkerPoolClicks{
+		BufferSize:    10,
+		BatchSize:     3,
+		FlushInterval: time.Hour,
+	})
+	recorder.Start(context.Background())
+
+	for i := 0; i < 4; i++ {
+		recorder.AddClick(model.Click{UUID: "aaa"})
+	}
+	assert.Eventually(t, func() bool { return len(svc.sizes()) == 1 }, time.Second, 10*time.Millisecond,
+		"full batch is saved without waiting for the interval")
+
+	recorder.Stop()
+	assert.Equal(t, []int{3, 1}, svc.sizes(), "remaining clicks are saved on stop")
+}
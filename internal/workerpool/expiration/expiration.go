@@ -0,0 +1,98 @@
+package expiration
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// URLService описывает интерфейс пометки ссылок с истёкшим сроком жизни.
+type URLService interface {
+	MarkExpired(ctx context.Context, now time.Time, limit int) (int, error)
+}
+
+// Sweeper структура фоновой пометки истёкших ссылок
+type Sweeper struct {
+	logger     *slog.Logger
+	wg         sync.WaitGroup
+	cancel     context.CancelFunc
+	URLService URLService
+	config     *model.WorkerPoolExpiration
+}
+
+// New конструктор Sweeper
+func New(svc URLService, log *slog.Logger, cfg *model.WorkerPoolExpiration) *Sweeper {
+	return &Sweeper{
+		logger:     log,
+		URLService: svc,
+		config:     cfg,
+	}
+}
+
+// Start запускает Sweeper
+func (s *Sweeper) Start(ctx context.Context) {
+	const op = "Sweeper.Start"
+	log := s.logger.With(
+		slog.String("op", op),
+	)
+	if s.config.Interval <= 0 || s.config.BatchSize <= 0 {
+		log.Info("Sweeper disabled")
+		return
+	}
+	log.Debug("Starting Sweeper")
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go s.run(ctx)
+}
+
+// Stop останавливает Sweeper
+func (s *Sweeper) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+func (s *Sweeper) run(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+// sweep помечает истёкшие ссылки пачками, пока пачка заполняется целиком
+func (s *Sweeper) sweep(ctx context.Context) {
+	const op = "Sweeper.sweep"
+	log := s.logger.With(
+		slog.String("op", op),
+	)
+	now := time.Now()
+	total := 0
+	for ctx.Err() == nil {
+		count, err := s.URLService.MarkExpired(ctx, now, s.config.BatchSize)
+		if err != nil {
+			log.Error("Batch processing failed", "error", err)
+			return
+		}
+		total += count
+		if count < s.config.BatchSize {
+			break
+		}
+	}
+	if total > 0 {
+		log.Info("Expired links marked", "count", total)
+	}
+}
@@ -0,0 +1,35 @@
+package expiration
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockURLService struct {
+	remaining int
+	calls     int
+}
+
+func (m *mockURLService) MarkExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	m.calls++
+	count := min(limit, m.remaining)
+	m.remaining -= count
+	return count, nil
+}
+
+func TestSweeper_Sweep(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := &mockURLService{remaining: 25}
+	sweeper := New(svc, logger, &model.WorkerPoolExpiration{Interval: time.Minute, BatchSize: 10})
+
+	sweeper.sweep(context.Background())
+
+	assert.Equal(t, 0, svc.remaining)
+	assert.Equal(t, 3, svc.calls)
+}
@@ -0,0 +1,112 @@
+package purge
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+)
+
+// URLService описывает интерфейс окончательного удаления ссылок.
+type URLService interface {
+	PurgeDeleted(ctx context.Context, now time.Time, limit int) (int, error)
+}
+
+// Purger структура фоновой очистки удалённых ссылок, срок хранения которых истёк
+type Purger struct {
+	logger     *slog.Logger
+	wg         sync.WaitGroup
+	cancel     context.CancelFunc
+	URLService URLService
+	config     *model.WorkerPoolPurge
+
+	mu    sync.Mutex
+	stats model.PurgeStats
+}
+
+// New конструктор Purger
+func New(svc URLService, log *slog.Logger, cfg *model.WorkerPoolPurge) *Purger {
+	return &Purger{
+		logger:     log,
+		URLService: svc,
+		config:     cfg,
+	}
+}
+
+// Start запускает Purger
+func (p *Purger) Start(ctx context.Context) {
+	const op = "Purger.Start"
+	log := p.logger.With(
+		slog.String("op", op),
+	)
+	if p.config.Interval <= 0 || p.config.BatchSize <= 0 {
+		log.Info("Purger disabled")
+		return
+	}
+	log.Debug("Starting Purger")
+	ctx, cancel := context.WithCancel(ctx)
+	p.cancel = cancel
+
+	p.wg.Add(1)
+	go p.run(ctx)
+}
+
+// Stop останавливает Purger
+func (p *Purger) Stop() {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wg.Wait()
+}
+
+// Stats результаты последнего запуска и общее число удалённых ссылок
+func (p *Purger) Stats() model.PurgeStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stats
+}
+
+func (p *Purger) run(ctx context.Context) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.purge(ctx)
+		}
+	}
+}
+
+// purge удаляет ссылки пачками, пока пачка заполняется целиком
+func (p *Purger) purge(ctx context.Context) {
+	const op = "Purger.purge"
+	log := p.logger.With(
+		slog.String("op", op),
+	)
+	now := time.Now()
+	total := 0
+	for ctx.Err() == nil {
+		count, err := p.URLService.PurgeDeleted(ctx, now, p.config.BatchSize)
+		total += count
+		if err != nil {
+			log.Error("Batch processing failed", "error", err)
+			break
+		}
+		if count < p.config.BatchSize {
+			break
+		}
+	}
+	log.Info("Deleted links purged", "count", total)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stats.LastRun = &now
+	p.stats.LastPurged = total
+	p.stats.TotalPurged += int64(total)
+}
@@ -0,0 +1,52 @@
+package purge
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockURLService struct {
+	remaining int
+	calls     int
+	failAt    int
+}
+
+func (m *mockURLService) PurgeDeleted(ctx context.Context, now time.Time, limit int) (int, error) {
+	m.calls++
+	if m.calls == m.failAt {
+		return 0, errors.New("database error")
+	}
+	count := min(limit, m.remaining)
+	m.remaining -= count
+	return count, nil
+}
+
+func TestPurger_Purge(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := &mockURLService{remaining: 25}
+	purger := New(svc, logger, &model.WorkerPoolPurge{Interval: time.Hour, BatchSize: 10})
+
+	purger.purge(context.Background())
+
+	assert.Equal(t, 0, svc.remaining)
+	assert.Equal(t, 3, svc.calls)
+	stats := purger.Stats()
+	require.NotNil(t, stats.LastRun)
+	assert.Equal(t, 25, stats.LastPurged)
+	assert.EqualValues(t, 25, stats.TotalPurged)
+
+	svc.remaining, svc.calls, svc.failAt = 30, 0, 2
+	purger.purge(context.Background())
+
+	stats = purger.Stats()
+	assert.Equal(t, 10, stats.LastPurged, "a failed batch stops the run but keeps what was purged")
+	assert.EqualValues(t, 35, stats.TotalPurged)
+}
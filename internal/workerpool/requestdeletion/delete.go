@@ -10,9 +10,10 @@ import (
 	"github.com/ArtShib/urlshortener/internal/model"
 )
 
-// URLService описывает интерфейс запросов на удаление.
+// URLService описывает интерфейс запросов на удаление и восстановление.
 type URLService interface {
 	DeleteBatch(ctx context.Context, batch model.URLUserRequestArray) error
+	RestoreBatch(ctx context.Context, batch model.URLUserRequestArray) error
 }
 
 // DeletePool структура WorkerPool
@@ -105,8 +106,9 @@ func (p *DeletePool) worker(ctx context.Context, id int, requests <-chan model.D
 			for _, uuid := range req.UUIDs {
 				select {
 				case p.inputCh <- model.URLUserRequest{
-					UUID:   uuid,
-					UserID: req.UserID,
+					UUID:    uuid,
+					UserID:  req.UserID,
+					Restore: req.Restore,
 				}:
 				default:
 					p.logger.Error("Input queue full, dropping request",
@@ -118,7 +120,7 @@ func (p *DeletePool) worker(ctx context.Context, id int, requests <-chan model.D
 	}
 }
 
-// AddRequest добавляет запрос на удаление в очередь обработки.
+// AddRequest добавляет запрос на удаление или восстановление в очередь обработки.
 func (p *DeletePool) AddRequest(req model.DeleteRequest) {
 	p.deleteRequests <- req
 }
@@ -198,13 +200,30 @@ func (p *DeletePool) flushBufferAsync(ctx context.Context, batch model.URLUserRe
 	p.processBatch(ctx, batch)
 }
 
+// processBatch применяет пачку частями из подряд идущих удалений или восстановлений,
+// чтобы удаление и восстановление одной ссылки выполнились в порядке поступления
 func (p *DeletePool) processBatch(ctx context.Context, batch model.URLUserRequestArray) {
-	if err := p.URLService.DeleteBatch(ctx, batch); err != nil {
-		p.logger.Error("Batch processing failed",
-			"error", err,
-			"batch_size", len(batch))
-	} else {
-		p.logger.Info("Batch processed successfully",
-			"batch_size", len(batch))
+	for start := 0; start < len(batch); {
+		end := start + 1
+		for end < len(batch) && batch[end].Restore == batch[start].Restore {
+			end++
+		}
+		run := batch[start:end]
+		start = end
+
+		apply, action := p.URLService.DeleteBatch, "delete"
+		if run[0].Restore {
+			apply, action = p.URLService.RestoreBatch, "restore"
+		}
+		if err := apply(ctx, run); err != nil {
+			p.logger.Error("Batch processing failed",
+				"error", err,
+				"action", action,
+				"batch_size", len(run))
+		} else {
+			p.logger.Info("Batch processed successfully",
+				"action", action,
+				"batch_size", len(run))
+		}
 	}
 }
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/ArtShib/urlshortener/internal/model"
+	"github.com/stretchr/testify/assert"
 )
 
 type MockURLService struct {
@@ -23,6 +24,43 @@ func (m *MockURLService) DeleteBatch(ctx context.Context, batch model.URLUserReq
 	return nil
 }
 
+func (m *MockURLService) RestoreBatch(ctx context.Context, batch model.URLUserRequestArray) error {
+	m.wg.Add(-len(batch))
+	return nil
+}
+
+type recordingURLService struct {
+	calls []string
+}
+
+func (m *recordingURLService) DeleteBatch(ctx context.Context, batch model.URLUserRequestArray) error {
+	for _, req := range batch {
+		m.calls = append(m.calls, "delete "+req.UUID)
+	}
+	return nil
+}
+
+func (m *recordingURLService) RestoreBatch(ctx context.Context, batch model.URLUserRequestArray) error {
+	for _, req := range batch {
+		m.calls = append(m.calls, "restore "+req.UUID)
+	}
+	return nil
+}
+
+func TestDeletePool_ProcessBatchKeepsOrder(t *testing.T) {
+	svc := &recordingURLService{}
+	pool := NewWorkerPool(svc, slog.New(slog.NewTextHandler(io.Discard, nil)), &model.WorkerPoolDelete{CountWorkers: 1})
+
+	pool.processBatch(context.Background(), model.URLUserRequestArray{
+		{UUID: "aaa"},
+		{UUID: "bbb"},
+		{UUID: "aaa", Restore: true},
+		{UUID: "ccc"},
+	})
+
+	assert.Equal(t, []string{"delete aaa", "delete bbb", "restore aaa", "delete ccc"}, svc.calls)
+}
+
 func BenchmarkWorkerPool_Delete(b *testing.B) {
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 
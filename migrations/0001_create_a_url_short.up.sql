@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS a_url_short (
+    id SERIAL PRIMARY KEY,
+    uuid text not null,
+    short_url text not null,
+    original_url text UNIQUE not null,
+    user_id text default null,
+    is_deleted boolean default false
+);
+CREATE INDEX IF NOT EXISTS idx_short_url_uuid ON a_url_short(uuid);
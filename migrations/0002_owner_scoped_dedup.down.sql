@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_a_url_short_user_id;
+DROP INDEX IF EXISTS uq_a_url_short_user_original_url;
+ALTER TABLE a_url_short DROP COLUMN IF EXISTS force_new;
+ALTER TABLE a_url_short ALTER COLUMN is_deleted DROP NOT NULL;
+ALTER TABLE a_url_short ADD CONSTRAINT a_url_short_original_url_key UNIQUE (original_url);
@@ -0,0 +1,8 @@
+ALTER TABLE a_url_short DROP CONSTRAINT IF EXISTS a_url_short_original_url_key;
+UPDATE a_url_short SET is_deleted = false WHERE is_deleted IS NULL;
+ALTER TABLE a_url_short ALTER COLUMN is_deleted SET NOT NULL;
+ALTER TABLE a_url_short ADD COLUMN IF NOT EXISTS force_new boolean NOT NULL DEFAULT false;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_a_url_short_user_original_url
+    ON a_url_short (user_id, original_url)
+    WHERE NOT force_new AND NOT is_deleted;
+CREATE INDEX IF NOT EXISTS idx_a_url_short_user_id ON a_url_short (user_id);
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_short_url_uuid ON a_url_short (uuid);
+DROP INDEX IF EXISTS uq_a_url_short_uuid;
@@ -0,0 +1,2 @@
+CREATE UNIQUE INDEX IF NOT EXISTS uq_a_url_short_uuid ON a_url_short (uuid);
+DROP INDEX IF EXISTS idx_short_url_uuid;
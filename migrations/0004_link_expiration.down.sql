@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS uq_a_url_short_user_original_url;
+CREATE UNIQUE INDEX uq_a_url_short_user_original_url
+    ON a_url_short (user_id, original_url)
+    WHERE NOT force_new AND NOT is_deleted;
+DROP INDEX IF EXISTS idx_a_url_short_expires_at;
+ALTER TABLE a_url_short DROP COLUMN IF EXISTS is_expired;
+ALTER TABLE a_url_short DROP COLUMN IF EXISTS expires_at;
@@ -0,0 +1,7 @@
+ALTER TABLE a_url_short ADD COLUMN IF NOT EXISTS expires_at timestamptz DEFAULT NULL;
+ALTER TABLE a_url_short ADD COLUMN IF NOT EXISTS is_expired boolean NOT NULL DEFAULT false;
+CREATE INDEX IF NOT EXISTS idx_a_url_short_expires_at ON a_url_short (expires_at) WHERE NOT is_expired;
+DROP INDEX IF EXISTS uq_a_url_short_user_original_url;
+CREATE UNIQUE INDEX uq_a_url_short_user_original_url
+    ON a_url_short (user_id, original_url)
+    WHERE NOT force_new AND NOT is_deleted AND NOT is_expired;
@@ -0,0 +1,2 @@
+ALTER TABLE a_url_short DROP COLUMN IF EXISTS clicks;
+ALTER TABLE a_url_short DROP COLUMN IF EXISTS max_clicks;
@@ -0,0 +1,2 @@
+ALTER TABLE a_url_short ADD COLUMN IF NOT EXISTS max_clicks integer NOT NULL DEFAULT 0;
+ALTER TABLE a_url_short ADD COLUMN IF NOT EXISTS clicks integer NOT NULL DEFAULT 0;
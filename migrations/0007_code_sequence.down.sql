@@ -0,0 +1 @@
+DROP SEQUENCE IF EXISTS a_url_short_code_seq;
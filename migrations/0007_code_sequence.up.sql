@@ -0,0 +1 @@
+CREATE SEQUENCE IF NOT EXISTS a_url_short_code_seq;
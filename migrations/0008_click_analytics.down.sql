@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS a_url_click_dimension;
+DROP TABLE IF EXISTS a_url_click_rollup;
+DROP TABLE IF EXISTS a_url_click;
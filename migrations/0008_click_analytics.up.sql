@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS a_url_click (
+    id bigserial PRIMARY KEY,
+    uuid text NOT NULL,
+    clicked_at timestamptz NOT NULL,
+    referrer text NOT NULL DEFAULT '',
+    agent text NOT NULL DEFAULT '',
+    country text NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_a_url_click_uuid_clicked_at ON a_url_click (uuid, clicked_at);
+
+CREATE TABLE IF NOT EXISTS a_url_click_rollup (
+    uuid text NOT NULL,
+    granularity text NOT NULL,
+    bucket timestamptz NOT NULL,
+    clicks bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY (uuid, granularity, bucket)
+);
+
+CREATE TABLE IF NOT EXISTS a_url_click_dimension (
+    uuid text NOT NULL,
+    dimension text NOT NULL,
+    value text NOT NULL,
+    clicks bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY (uuid, dimension, value)
+);
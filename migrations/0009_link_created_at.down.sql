@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS idx_a_url_short_user_id ON a_url_short (user_id);
+DROP INDEX IF EXISTS idx_a_url_short_user_created;
+ALTER TABLE a_url_short DROP COLUMN IF EXISTS created_at;
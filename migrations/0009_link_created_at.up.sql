@@ -0,0 +1,3 @@
+ALTER TABLE a_url_short ADD COLUMN IF NOT EXISTS created_at timestamptz NOT NULL DEFAULT now();
+CREATE INDEX IF NOT EXISTS idx_a_url_short_user_created ON a_url_short (user_id, created_at, uuid);
+DROP INDEX IF EXISTS idx_a_url_short_user_id;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS a_url_history;
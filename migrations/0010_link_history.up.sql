@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS a_url_history (
+    id bigserial PRIMARY KEY,
+    uuid text NOT NULL,
+    original_url text NOT NULL,
+    changed_at timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_a_url_history_uuid ON a_url_history (uuid, changed_at);
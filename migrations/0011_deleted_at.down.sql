@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_a_url_short_deleted_at;
+ALTER TABLE a_url_short DROP COLUMN IF EXISTS deleted_at;
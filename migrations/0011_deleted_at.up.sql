@@ -0,0 +1,3 @@
+ALTER TABLE a_url_short ADD COLUMN IF NOT EXISTS deleted_at timestamptz DEFAULT NULL;
+UPDATE a_url_short SET deleted_at = now() WHERE is_deleted AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_a_url_short_deleted_at ON a_url_short (deleted_at) WHERE is_deleted;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_a_url_short_search;
+DROP INDEX IF EXISTS idx_a_url_short_tags;
+ALTER TABLE a_url_short DROP COLUMN IF EXISTS note;
+ALTER TABLE a_url_short DROP COLUMN IF EXISTS title;
+ALTER TABLE a_url_short DROP COLUMN IF EXISTS tags;
@@ -0,0 +1,7 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+ALTER TABLE a_url_short ADD COLUMN IF NOT EXISTS tags text[] NOT NULL DEFAULT '{}';
+ALTER TABLE a_url_short ADD COLUMN IF NOT EXISTS title text NOT NULL DEFAULT '';
+ALTER TABLE a_url_short ADD COLUMN IF NOT EXISTS note text NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_a_url_short_tags ON a_url_short USING gin (tags);
+CREATE INDEX IF NOT EXISTS idx_a_url_short_search ON a_url_short
+    USING gin (lower(original_url || ' ' || title || ' ' || note) gin_trgm_ops);
@@ -0,0 +1 @@
+ALTER TABLE a_url_short DROP COLUMN IF EXISTS targets;
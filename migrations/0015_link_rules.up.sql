@@ -0,0 +1 @@
+ALTER TABLE a_url_short ADD COLUMN IF NOT EXISTS rules jsonb;
@@ -0,0 +1,2 @@
+ALTER TABLE a_url_short DROP COLUMN IF EXISTS utm;
+ALTER TABLE a_url_short DROP COLUMN IF EXISTS passthrough;
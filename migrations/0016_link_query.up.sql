@@ -0,0 +1,2 @@
+ALTER TABLE a_url_short ADD COLUMN IF NOT EXISTS passthrough boolean NOT NULL DEFAULT false;
+ALTER TABLE a_url_short ADD COLUMN IF NOT EXISTS utm jsonb;
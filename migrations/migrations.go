@@ -0,0 +1,12 @@
+// Package migrations содержит версионные SQL миграции схемы БД.
+//
+// Файлы именуются как <version>_<name>.up.sql и <version>_<name>.down.sql
+// и применяются в порядке возрастания version.
+package migrations
+
+import "embed"
+
+// FS встроенные в бинарь файлы миграций
+//
+//go:embed *.sql
+var FS embed.FS